@@ -3,16 +3,27 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/caarlos0/env/v11"
+	"github.com/disgoorg/snowflake/v2"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Token            string `env:"DISCORD_TOKEN,required"`
-	LavalinkHost     string `env:"LAVALINK_HOST,required"`
-	LavalinkPassword string `env:"LAVALINK_PASSWORD,required"`
-	Prefix           string `env:"BOT_PREFIX" envDefault:"!"`
+	Token               string         `env:"DISCORD_TOKEN,required"`
+	LavalinkHost        string         `env:"LAVALINK_HOST,required"`
+	LavalinkPassword    string         `env:"LAVALINK_PASSWORD,required"`
+	Prefix              string         `env:"BOT_PREFIX" envDefault:"!"`
+	Owners              []snowflake.ID `env:"BOT_OWNERS" envSeparator:","`
+	CooldownBypassRoles []snowflake.ID `env:"COOLDOWN_BYPASS_ROLES" envSeparator:","`
+	DevGuilds           []snowflake.ID `env:"DEV_GUILDS" envSeparator:","`
+	SettingsPath        string         `env:"SETTINGS_PATH" envDefault:"data/settings.json"`
+	EditTracking        time.Duration  `env:"EDIT_TRACKING" envDefault:"5m"`
+	Language            string         `env:"BOT_LANGUAGE" envDefault:"en-US"`
+	MetricsPath         string         `env:"METRICS_PATH" envDefault:"data/metrics.json"`
+	MetricsInterval     time.Duration  `env:"METRICS_INTERVAL" envDefault:"5m"`
+	ErrorChannel        snowflake.ID   `env:"ERROR_CHANNEL"`
 }
 
 func Load() (*Config, error) {
@@ -0,0 +1,269 @@
+// Package i18n holds the message catalog used for command metadata and
+// responses.
+//
+// Catalogs are JSON files named after a Discord locale, mapping keys to either
+// a format string or, for pluralized messages, an object of CLDR plural
+// categories ("one", "few", "many", "other") to format strings. Format strings
+// use fmt verbs and may reorder arguments with explicit indexes (%[2]s).
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// message is a plain string or a set of plural forms.
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &m.plural)
+}
+
+type Catalog struct {
+	fallback discord.Locale
+	messages map[discord.Locale]map[string]message
+}
+
+// Embedded loads the catalogs shipped with the bot.
+func Embedded(fallback discord.Locale) (*Catalog, error) {
+	return Load(locales, "locales", fallback)
+}
+
+// MustEmbedded is like Embedded but panics when the shipped catalogs are
+// invalid.
+func MustEmbedded(fallback discord.Locale) *Catalog {
+	c, err := Embedded(fallback)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Load reads every <locale>.json file in dir. The fallback locale must be one
+// of them, its messages are used when a locale is missing a key.
+func Load(fsys fs.FS, dir string, fallback discord.Locale) (*Catalog, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list catalogs: %w", err)
+	}
+
+	c := &Catalog{
+		fallback: fallback,
+		messages: make(map[discord.Locale]map[string]message, len(files)),
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog %s: %w", file, err)
+		}
+
+		var messages map[string]message
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse catalog %s: %w", file, err)
+		}
+		c.messages[discord.Locale(strings.TrimSuffix(path.Base(file), ".json"))] = messages
+	}
+
+	if _, ok := c.messages[fallback]; !ok {
+		return nil, fmt.Errorf("missing catalog for fallback locale %s", fallback)
+	}
+	return c, nil
+}
+
+func (c *Catalog) Fallback() discord.Locale {
+	return c.fallback
+}
+
+// Locales returns the locales with a catalog, sorted.
+func (c *Catalog) Locales() []discord.Locale {
+	locales := make([]discord.Locale, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// Supports reports whether the locale has a catalog.
+func (c *Catalog) Supports(locale discord.Locale) bool {
+	_, ok := c.messages[locale]
+	return ok
+}
+
+// T formats the message for key in the given locale. Missing keys fall back to
+// the same language in another region, then to the fallback locale and finally
+// to the key itself.
+func (c *Catalog) T(locale discord.Locale, key string, args ...any) string {
+	msg, _, ok := c.lookup(locale, key)
+	if !ok {
+		return key
+	}
+
+	text := msg.text
+	if msg.plural != nil {
+		text = msg.plural["other"]
+	}
+	return format(text, args)
+}
+
+// N formats the plural form of key matching count. The count is passed as the
+// first argument, followed by args.
+func (c *Catalog) N(locale discord.Locale, key string, count int, args ...any) string {
+	msg, found, ok := c.lookup(locale, key)
+	if !ok {
+		return key
+	}
+
+	args = append([]any{count}, args...)
+	if msg.plural == nil {
+		return format(msg.text, args)
+	}
+
+	text, ok := msg.plural[pluralCategory(found, count)]
+	if !ok {
+		text = msg.plural["other"]
+	}
+	return format(text, args)
+}
+
+// Lookup returns the unformatted message for key, reporting whether any
+// catalog has it.
+func (c *Catalog) Lookup(locale discord.Locale, key string) (string, bool) {
+	msg, _, ok := c.lookup(locale, key)
+	if !ok {
+		return "", false
+	}
+	if msg.plural != nil {
+		return msg.plural["other"], true
+	}
+	return msg.text, true
+}
+
+// Localizations returns the translations of key in every locale except the
+// fallback one, as expected by the *Localizations fields of application
+// commands.
+func (c *Catalog) Localizations(key string) map[discord.Locale]string {
+	var localizations map[discord.Locale]string
+	for locale, messages := range c.messages {
+		msg, ok := messages[key]
+		if !ok || locale == c.fallback || msg.text == "" {
+			continue
+		}
+		if localizations == nil {
+			localizations = make(map[discord.Locale]string)
+		}
+		localizations[locale] = msg.text
+	}
+	return localizations
+}
+
+func (c *Catalog) lookup(locale discord.Locale, key string) (message, discord.Locale, bool) {
+	if msg, ok := c.messages[locale][key]; ok {
+		return msg, locale, true
+	}
+
+	language := languageOf(locale)
+	for _, other := range c.Locales() {
+		if other != locale && languageOf(other) == language {
+			if msg, ok := c.messages[other][key]; ok {
+				return msg, other, true
+			}
+		}
+	}
+
+	msg, ok := c.messages[c.fallback][key]
+	return msg, c.fallback, ok
+}
+
+func format(text string, args []any) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+func languageOf(locale discord.Locale) string {
+	language, _, _ := strings.Cut(string(locale), "-")
+	return language
+}
+
+// pluralCategory implements the CLDR cardinal rules for integers of the
+// languages Discord supports.
+func pluralCategory(locale discord.Locale, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch languageOf(locale) {
+	case "ja", "ko", "zh", "th", "vi", "id":
+		return "other"
+	case "pt", "fr", "hi":
+		if n <= 1 {
+			return "one"
+		}
+	case "ru", "uk", "hr", "pl", "lt", "cs":
+		return slavicCategory(languageOf(locale), n)
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+func slavicCategory(language string, n int) string {
+	mod10, mod100 := n%10, n%100
+
+	switch language {
+	case "cs":
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		}
+		return "other"
+	case "lt":
+		switch {
+		case mod10 == 1 && (mod100 < 11 || mod100 > 19):
+			return "one"
+		case mod10 >= 2 && (mod100 < 11 || mod100 > 19):
+			return "few"
+		}
+		return "other"
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+		return "many"
+	}
+
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return "one"
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return "few"
+	case language == "hr":
+		return "other"
+	}
+	return "many"
+}
@@ -0,0 +1,216 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+func testCatalog(t *testing.T) *Catalog {
+	t.Helper()
+
+	fsys := fstest.MapFS{
+		"locales/en-US.json": {Data: []byte(`{
+			"greeting": "Hello, %s!",
+			"only_english": "Only in English, %s",
+			"songs": {"one": "%d song", "other": "%d songs"},
+			"songs_by": {"one": "%d song by %s", "other": "%d songs by %s"},
+			"plain_count": "%d items"
+		}`)},
+		"locales/pt-BR.json": {Data: []byte(`{
+			"greeting": "Olá, %s!",
+			"only_portuguese": "Só em português, %s",
+			"songs": {"one": "%d música", "other": "%d músicas"}
+		}`)},
+		"locales/pt-PT.json": {Data: []byte(`{
+			"greeting": "Olá, %s! (PT)",
+			"only_portugal": "Só em Portugal, %s"
+		}`)},
+		"locales/pl.json": {Data: []byte(`{
+			"songs": {"one": "%d utwór", "few": "%d utwory", "many": "%d utworów"}
+		}`)},
+		"locales/ru.json": {Data: []byte(`{
+			"songs": {"one": "%d песня", "other": "%d песен"}
+		}`)},
+		"locales/ja.json": {Data: []byte(`{
+			"songs": {"other": "%d曲"}
+		}`)},
+	}
+
+	c, err := Load(fsys, "locales", discord.LocaleEnglishUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/pt-BR.json": {Data: []byte(`{"greeting": "Olá"}`)},
+	}
+
+	if _, err := Load(fsys, "locales", discord.LocaleEnglishUS); err == nil {
+		t.Error("loaded catalogs without the fallback locale")
+	}
+
+	fsys["locales/en-US.json"] = &fstest.MapFile{Data: []byte(`{}`)}
+	if _, err := Load(fsys, "locales", discord.LocaleEnglishUS); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	fsys["locales/pt-PT.json"] = &fstest.MapFile{Data: []byte(`{"greeting": `)}
+	if _, err := Load(fsys, "locales", discord.LocaleEnglishUS); err == nil {
+		t.Error("loaded an invalid catalog")
+	}
+}
+
+func TestT(t *testing.T) {
+	c := testCatalog(t)
+
+	tests := []struct {
+		name   string
+		locale discord.Locale
+		key    string
+		want   string
+	}{
+		{name: "own locale", locale: discord.LocalePortugueseBR, key: "greeting", want: "Olá, Ana!"},
+		{name: "fallback locale", locale: discord.LocaleEnglishUS, key: "greeting", want: "Hello, Ana!"},
+		{name: "missing key uses the fallback", locale: discord.LocalePortugueseBR, key: "only_english", want: "Only in English, Ana"},
+		{name: "same language in another region", locale: discord.LocalePortugueseBR, key: "only_portugal", want: "Só em Portugal, Ana"},
+		{name: "unknown locale of a known language", locale: "pt-AO", key: "only_portuguese", want: "Só em português, Ana"},
+		{name: "unknown locale", locale: discord.LocaleGerman, key: "greeting", want: "Hello, Ana!"},
+		{name: "empty locale", locale: "", key: "greeting", want: "Hello, Ana!"},
+		{name: "fallback has no other language's keys", locale: discord.LocaleEnglishUS, key: "only_portuguese", want: "only_portuguese"},
+		{name: "missing everywhere", locale: discord.LocalePortugueseBR, key: "nope", want: "nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.T(tt.locale, tt.key, "Ana"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestN(t *testing.T) {
+	c := testCatalog(t)
+
+	tests := []struct {
+		name   string
+		locale discord.Locale
+		key    string
+		count  int
+		args   []any
+		want   string
+	}{
+		{name: "english one", locale: discord.LocaleEnglishUS, key: "songs", count: 1, want: "1 song"},
+		{name: "english zero", locale: discord.LocaleEnglishUS, key: "songs", count: 0, want: "0 songs"},
+		{name: "english many", locale: discord.LocaleEnglishUS, key: "songs", count: 21, want: "21 songs"},
+		{name: "portuguese zero is one", locale: discord.LocalePortugueseBR, key: "songs", count: 0, want: "0 música"},
+		{name: "portuguese two", locale: discord.LocalePortugueseBR, key: "songs", count: 2, want: "2 músicas"},
+		{name: "polish few", locale: discord.LocalePolish, key: "songs", count: 3, want: "3 utwory"},
+		{name: "polish many", locale: discord.LocalePolish, key: "songs", count: 12, want: "12 utworów"},
+		{name: "polish few again", locale: discord.LocalePolish, key: "songs", count: 22, want: "22 utwory"},
+		{name: "japanese", locale: discord.LocaleJapanese, key: "songs", count: 1, want: "1曲"},
+		{name: "extra arguments", locale: discord.LocaleEnglishUS, key: "songs_by", count: 1, args: []any{"Rick"}, want: "1 song by Rick"},
+		// The fallback's forms are picked with the fallback's rules: 0 is
+		// "one" in Portuguese but not in English.
+		{name: "fallback rules", locale: discord.LocalePortugueseBR, key: "songs_by", count: 0, args: []any{"Rick"}, want: "0 songs by Rick"},
+		{name: "same language in another region", locale: "pt-PT", key: "songs", count: 1, want: "1 música"},
+		// Catalogs may leave out categories the language doesn't need.
+		{name: "missing category uses other", locale: discord.LocaleRussian, key: "songs", count: 3, want: "3 песен"},
+		{name: "unknown locale", locale: discord.LocaleGerman, key: "songs", count: 5, want: "5 songs"},
+		{name: "not pluralized", locale: discord.LocaleEnglishUS, key: "plain_count", count: 1, want: "1 items"},
+		{name: "missing key", locale: discord.LocaleEnglishUS, key: "nope", count: 1, want: "nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.N(tt.locale, tt.key, tt.count, tt.args...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale discord.Locale
+		counts map[int]string
+	}{
+		{locale: discord.LocaleEnglishUS, counts: map[int]string{0: "other", 1: "one", 2: "other", 11: "other", 21: "other", -1: "one"}},
+		{locale: discord.LocalePortugueseBR, counts: map[int]string{0: "one", 1: "one", 2: "other"}},
+		{locale: discord.LocaleFrench, counts: map[int]string{0: "one", 1: "one", 2: "other"}},
+		{locale: discord.LocaleJapanese, counts: map[int]string{0: "other", 1: "other", 2: "other"}},
+		{locale: discord.LocaleRussian, counts: map[int]string{
+			1: "one", 21: "one", 11: "many", 2: "few", 24: "few", 12: "many", 5: "many", 0: "many", 111: "many",
+		}},
+		{locale: discord.LocaleUkrainian, counts: map[int]string{1: "one", 3: "few", 13: "many"}},
+		{locale: discord.LocaleCroatian, counts: map[int]string{1: "one", 21: "one", 2: "few", 5: "other", 11: "other"}},
+		{locale: discord.LocalePolish, counts: map[int]string{1: "one", 21: "many", 2: "few", 22: "few", 12: "many", 5: "many", 0: "many"}},
+		{locale: discord.LocaleCzech, counts: map[int]string{1: "one", 2: "few", 4: "few", 5: "other", 22: "other"}},
+		{locale: discord.LocaleLithuanian, counts: map[int]string{1: "one", 21: "one", 11: "other", 2: "few", 19: "other", 10: "other"}},
+	}
+
+	for _, tt := range tests {
+		for n, want := range tt.counts {
+			if got := pluralCategory(tt.locale, n); got != want {
+				t.Errorf("pluralCategory(%s, %d) = %q, want %q", tt.locale, n, got, want)
+			}
+		}
+	}
+}
+
+func TestSupports(t *testing.T) {
+	c := testCatalog(t)
+
+	for locale, want := range map[discord.Locale]bool{
+		discord.LocaleEnglishUS:    true,
+		discord.LocalePortugueseBR: true,
+		discord.LocaleGerman:       false,
+		"pt":                       false,
+		"":                         false,
+	} {
+		if got := c.Supports(locale); got != want {
+			t.Errorf("Supports(%q) = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+func TestLocalizations(t *testing.T) {
+	c := testCatalog(t)
+
+	got := c.Localizations("greeting")
+	want := map[discord.Locale]string{discord.LocalePortugueseBR: "Olá, %s!", "pt-PT": "Olá, %s! (PT)"}
+	if len(got) != len(want) || got[discord.LocalePortugueseBR] != want[discord.LocalePortugueseBR] || got["pt-PT"] != want["pt-PT"] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := c.Localizations("songs"); got != nil {
+		t.Errorf("got %v for a pluralized key, want none", got)
+	}
+}
+
+// TestEmbedded checks that the shipped catalogs load and that the translated
+// responses don't have keys en-US lacks, which would be typos. Command metadata
+// is written in code, so en-US has no commands.* keys.
+func TestEmbedded(t *testing.T) {
+	c, err := Embedded(discord.LocaleEnglishUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, locale := range c.Locales() {
+		for key := range c.messages[locale] {
+			if strings.HasPrefix(key, "commands.") {
+				continue
+			}
+			if _, ok := c.messages[discord.LocaleEnglishUS][key]; !ok {
+				t.Errorf("%s has %s, en-US doesn't", locale, key)
+			}
+		}
+	}
+}
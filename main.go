@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -13,15 +12,18 @@ import (
 	"github.com/disgoorg/disgo"
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/snowflake/v2"
 
 	"github.com/goland-express/flexo/config"
+	"github.com/goland-express/flexo/i18n"
 	"github.com/goland-express/flexo/modules"
 	"github.com/goland-express/flexo/player"
 	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/settings"
 	"github.com/goland-express/flexo/types"
-	"github.com/goland-express/flexo/utils"
 )
 
 func main() {
@@ -31,35 +33,71 @@ func main() {
 		os.Exit(1)
 	}
 
+	store, err := settings.Open(cfg.SettingsPath)
+	if err != nil {
+		slog.Error("Failed to load settings", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	metrics, err := registry.LoadMetrics(cfg.MetricsPath)
+	if err != nil {
+		slog.Error("Failed to load metrics", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	botData := &types.BotData{
 		StartTime: time.Now(),
+		Settings:  store,
 	}
 
+	catalog, err := i18n.Embedded(discord.Locale(cfg.Language))
+	if err != nil {
+		slog.Error("Failed to load translations", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	settingsModule := &modules.SettingsModule{DefaultPrefix: cfg.Prefix, Catalog: catalog}
+
 	reg := registry.New(registry.Options{
-		Data:   botData,
-		Prefix: cfg.Prefix,
-		OnError: func(err error, ctx *registry.Context) {
-			var userErr *utils.UserError
-			if errors.As(err, &userErr) {
-				_ = ctx.Reply(userErr.Message)
-				return
+		Data:                botData,
+		Prefix:              cfg.Prefix,
+		Owners:              cfg.Owners,
+		CooldownBypassRoles: cfg.CooldownBypassRoles,
+		DevGuilds:           cfg.DevGuilds,
+		MentionPrefix:       true,
+		EditTracking:        cfg.EditTracking,
+		PrefixResolver: func(event *events.MessageCreate) []string {
+			if event.GuildID == nil {
+				return []string{cfg.Prefix}
 			}
-
-			slog.Error("An unexpected error occurred",
-				slog.Any("error", err),
-				slog.String("guild_id", ctx.GuildID().String()),
-				slog.String("author_id", ctx.Author().ID.String()),
-			)
-			_ = ctx.Reply("An unexpected error occurred while running this command.")
+			return settingsModule.Prefixes(store.Guild(*event.GuildID))
+		},
+		SuggestCommands: func(guildID *snowflake.ID) bool {
+			return guildID == nil || !store.Guild(*guildID).DisableSuggestions
 		},
+		Catalog: catalog,
+		Checks:  []registry.CheckFunc{settingsModule.CheckPolicy},
+		Metrics: metrics,
+		GuildLocale: func(guildID snowflake.ID) discord.Locale {
+			return settingsModule.Locale(store.Guild(guildID))
+		},
+		ErrorChannel: cfg.ErrorChannel,
 	})
 
+	reg.Use(
+		registry.Logger(slog.Default()),
+		registry.Recover(),
+	)
+
 	loadedModules := []modules.Module{
+		&modules.HelpModule{},
 		&modules.MusicModule{},
+		settingsModule,
+		&modules.StatsModule{},
 	}
 
 	for _, module := range loadedModules {
-		module.Register(reg)
+		reg.LoadModule(module.Name(), module.Register)
 		slog.Info("Module loaded", slog.String("module", module.Name()))
 	}
 
@@ -73,10 +111,16 @@ func main() {
 			),
 		),
 		bot.WithCacheConfigOpts(
-			cache.WithCaches(cache.FlagGuilds, cache.FlagVoiceStates),
+			cache.WithCaches(registry.CacheFlags),
 		),
+		// Commands waiting for a confirmation would otherwise block the click.
+		bot.WithEventManagerConfigOpts(bot.WithAsyncEventsEnabled()),
 		bot.WithEventListenerFunc(reg.OnMessage),
+		bot.WithEventListenerFunc(reg.OnMessageUpdate),
 		bot.WithEventListenerFunc(reg.OnSlashCommand),
+		bot.WithEventListenerFunc(reg.OnAutocomplete),
+		bot.WithEventListenerFunc(reg.OnComponent),
+		bot.WithEventListenerFunc(reg.OnModalSubmit),
 		bot.WithEventListenerFunc(reg.OnReady),
 		bot.WithEventListenerFunc(func(event *events.Ready) {
 			slog.Info("Bot is ready",
@@ -115,8 +159,18 @@ func main() {
 		return
 	}
 
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	metricsSaved := make(chan struct{})
+	go func() {
+		metrics.SaveEvery(metricsCtx, cfg.MetricsPath, cfg.MetricsInterval)
+		close(metricsSaved)
+	}()
+
 	slog.Info("Bot is running. Press CTRL-C to exit.")
 	s := make(chan os.Signal, 1)
 	signal.Notify(s, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	<-s
+
+	stopMetrics()
+	<-metricsSaved
 }
@@ -0,0 +1,239 @@
+package modules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/utils"
+)
+
+const helpCommandsPerPage = 8
+
+type HelpModule struct {
+	registry *registry.Registry
+}
+
+func (m *HelpModule) Name() string {
+	return "General"
+}
+
+func (m *HelpModule) Register(r *registry.Registry) {
+	m.registry = r
+
+	r.Add(&registry.Command{
+		Name:          "help",
+		Description:   "Show the available commands or details about one of them.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		Aliases:       []string{"h", "commands"},
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionInt{Name: "page", Description: "Page of the command list", MinValue: utils.Ptr(1)},
+			discord.ApplicationCommandOptionString{Name: "command", Description: "Command to show details for"},
+		},
+		Examples: []string{"help", "help 2", "help play", "help queue remove"},
+		Execute:  m.executeHelp,
+	})
+}
+
+func (m *HelpModule) executeHelp(ctx *registry.Context) error {
+	if name, ok := ctx.GetStringOption("command"); ok && name != "" {
+		path := m.findCommand(strings.Fields(strings.ToLower(name)))
+		if path == nil {
+			return &utils.UserError{Kind: utils.KindNotFound, Message: ctx.T("help.not_found", name)}
+		}
+
+		if err := ctx.SendEmbed(buildCommandHelpEmbed(ctx, path)); err != nil {
+			return fmt.Errorf("failed to send embed: %w", err)
+		}
+		return nil
+	}
+
+	pages := m.buildOverviewPages(ctx)
+	page, ok := ctx.GetIntOption("page")
+	if !ok {
+		page = 1
+	}
+	if page < 1 || int(page) > len(pages) {
+		return &utils.UserError{Message: ctx.T("help.invalid_page", len(pages))}
+	}
+
+	return ctx.Paginate(registry.Paginator{
+		Pages: len(pages),
+		Page: func(page int) (discord.Embed, error) {
+			return pages[page], nil
+		},
+		Start: int(page - 1),
+	})
+}
+
+func (m *HelpModule) findCommand(names []string) []*registry.Command {
+	if len(names) == 0 {
+		return nil
+	}
+
+	cmd := m.registry.Get(names[0])
+	if cmd == nil || cmd.Hidden {
+		return nil
+	}
+
+	path := []*registry.Command{cmd}
+	for _, name := range names[1:] {
+		sub := cmd.SubCommand(name)
+		if sub == nil || sub.Hidden {
+			return nil
+		}
+		cmd = sub
+		path = append(path, sub)
+	}
+	return path
+}
+
+func (m *HelpModule) visibleCommands(ctx *registry.Context, module string) []*registry.Command {
+	var commands []*registry.Command
+	for _, cmd := range m.registry.Commands() {
+		if cmd.Hidden || cmd.Module != module {
+			continue
+		}
+		if ctx.IsSlash() && !cmd.SlashCommand || !ctx.IsSlash() && !cmd.PrefixCommand {
+			continue
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+func (m *HelpModule) buildOverviewPages(ctx *registry.Context) []discord.Embed {
+	type section struct {
+		module   string
+		commands []*registry.Command
+	}
+
+	var sections []section
+	for _, module := range m.registry.Modules() {
+		commands := m.visibleCommands(ctx, module)
+		for len(commands) > 0 {
+			n := min(len(commands), helpCommandsPerPage)
+			sections = append(sections, section{module: module, commands: commands[:n]})
+			commands = commands[n:]
+		}
+	}
+
+	if len(sections) == 0 {
+		return []discord.Embed{
+			discord.NewEmbedBuilder().
+				SetColor(0x5865F2).
+				SetDescription(ctx.T("help.empty")).
+				Build(),
+		}
+	}
+
+	pages := make([]discord.Embed, len(sections))
+	for i, s := range sections {
+		embed := discord.NewEmbedBuilder().
+			SetTitle(ctx.T("help.title", ctx.Localize("modules."+s.module, s.module))).
+			SetColor(0x5865F2).
+			SetFooter(ctx.T("help.footer", i+1, len(sections), ctx.Prefix()), "").
+			SetTimestamp(time.Now())
+
+		for _, cmd := range s.commands {
+			embed.AddField(commandSignature(ctx, []*registry.Command{cmd}), commandSummary(ctx, cmd), false)
+		}
+		pages[i] = embed.Build()
+	}
+	return pages
+}
+
+func buildCommandHelpEmbed(ctx *registry.Context, path []*registry.Command) discord.Embed {
+	cmd := path[len(path)-1]
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(ctx.Prefix() + commandName(path)).
+		SetDescription(describe(ctx, path)).
+		SetColor(0x5865F2).
+		SetTimestamp(time.Now())
+
+	if cmd.Module != "" {
+		embed.SetFooter(ctx.T("help.module", ctx.Localize("modules."+cmd.Module, cmd.Module)), "")
+	}
+
+	if cmd.Execute != nil && (len(cmd.SubCommands) == 0 || !ctx.IsSlash()) {
+		embed.AddField(ctx.T("help.usage"), "`"+commandSignature(ctx, path)+"`", false)
+	}
+
+	if len(cmd.Aliases) > 0 {
+		aliases := make([]string, len(cmd.Aliases))
+		for i, alias := range cmd.Aliases {
+			aliases[i] = "`" + alias + "`"
+		}
+		embed.AddField(ctx.T("help.aliases"), strings.Join(aliases, ", "), false)
+	}
+
+	if len(cmd.Options) > 0 {
+		var sb strings.Builder
+		for _, option := range cmd.Options {
+			description := ctx.Localize(registry.CommandKey(path)+".options."+option.OptionName()+".description", option.OptionDescription())
+			sb.WriteString(fmt.Sprintf("`%s` — %s\n", option.OptionName(), description))
+		}
+		embed.AddField(ctx.T("help.options"), sb.String(), false)
+	}
+
+	if len(cmd.SubCommands) > 0 {
+		var sb strings.Builder
+		for _, sub := range cmd.SubCommands {
+			if sub.Hidden {
+				continue
+			}
+			subPath := append(append([]*registry.Command{}, path...), sub)
+			sb.WriteString(fmt.Sprintf("`%s` — %s\n", commandSignature(ctx, subPath), describe(ctx, subPath)))
+		}
+		embed.AddField(ctx.T("help.subcommands"), sb.String(), false)
+	}
+
+	if len(cmd.Examples) > 0 {
+		examples := make([]string, len(cmd.Examples))
+		for i, example := range cmd.Examples {
+			examples[i] = "`" + ctx.Prefix() + example + "`"
+		}
+		embed.AddField(ctx.T("help.examples"), strings.Join(examples, "\n"), false)
+	}
+
+	return embed.Build()
+}
+
+func commandName(path []*registry.Command) string {
+	names := make([]string, len(path))
+	for i, cmd := range path {
+		names[i] = cmd.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func commandSignature(ctx *registry.Context, path []*registry.Command) string {
+	cmd := path[len(path)-1]
+	signature := ctx.Prefix() + commandName(path)
+
+	if len(cmd.SubCommands) > 0 {
+		return signature + " <subcommand>"
+	}
+	if usage := cmd.Usage(); usage != "" {
+		return signature + " " + usage
+	}
+	return signature
+}
+
+func commandSummary(ctx *registry.Context, cmd *registry.Command) string {
+	summary := describe(ctx, []*registry.Command{cmd})
+	if len(cmd.Aliases) > 0 {
+		summary += "\n" + ctx.T("help.aliases") + ": " + strings.Join(cmd.Aliases, ", ")
+	}
+	return summary
+}
+
+// describe returns the command's description in the context's locale.
+func describe(ctx *registry.Context, path []*registry.Command) string {
+	return ctx.Localize(registry.CommandKey(path)+".description", path[len(path)-1].Description)
+}
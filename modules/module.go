@@ -1,6 +1,13 @@
 package modules
 
-import "github.com/goland-express/flexo/registry"
+import (
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/registry"
+)
+
+// guildContexts keep commands that only work in servers out of DMs.
+var guildContexts = []discord.InteractionContextType{discord.InteractionContextTypeGuild}
 
 type Module interface {
 	Name() string
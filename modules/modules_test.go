@@ -0,0 +1,68 @@
+package modules_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/i18n"
+	"github.com/goland-express/flexo/modules"
+	"github.com/goland-express/flexo/player/playertest"
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/registry/registrytest"
+	"github.com/goland-express/flexo/settings"
+	"github.com/goland-express/flexo/types"
+)
+
+const (
+	voiceChannelID snowflake.ID = 300
+
+	// bypassRoleID skips cooldowns, which tests running commands back to back
+	// would otherwise hit.
+	bypassRoleID snowflake.ID = 400
+)
+
+// testBot is the bot main.go puts together, with a fake player and the
+// author in a voice channel.
+type testBot struct {
+	*registrytest.Harness
+	Player   *playertest.Player
+	Settings *settings.Store
+}
+
+func newTestBot(t *testing.T, opts registry.Options) *testBot {
+	t.Helper()
+
+	store, err := settings.Open(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := &testBot{Player: playertest.New(), Settings: store}
+	settingsModule := &modules.SettingsModule{DefaultPrefix: "!", Catalog: i18n.MustEmbedded(discord.LocaleEnglishUS)}
+
+	opts.Data = &types.BotData{Player: bot.Player, Settings: store}
+	opts.Prefix = "!"
+	opts.Checks = append(opts.Checks, settingsModule.CheckPolicy)
+	opts.CooldownBypassRoles = append(opts.CooldownBypassRoles, bypassRoleID)
+	bot.Harness = registrytest.New(opts)
+	bot.Roles = []snowflake.ID{bypassRoleID}
+
+	for _, module := range []modules.Module{&modules.HelpModule{}, &modules.MusicModule{}, settingsModule} {
+		bot.Registry.LoadModule(module.Name(), module.Register)
+	}
+
+	bot.JoinVoice(bot.Author.ID, voiceChannelID)
+	return bot
+}
+
+// run fails the test when the invocation ended in an error.
+func (b *testBot) run(t *testing.T, result *registrytest.Result) *registrytest.Result {
+	t.Helper()
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	return result
+}
@@ -6,12 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgolink/v3/lavalink"
-	"github.com/disgoorg/snowflake/v2"
 
 	"github.com/goland-express/flexo/player"
 	"github.com/goland-express/flexo/registry"
@@ -21,6 +22,30 @@ import (
 
 var ErrInvalidBotData = errors.New("invalid bot data type")
 
+var trackLinkPattern = regexp.MustCompile(`https?://(?:(?:www|m|music)\.)?(?:youtube\.com|youtu\.be|open\.spotify\.com)/[^\s<>]+`)
+
+const (
+	autocompleteTimeout  = 2500 * time.Millisecond
+	maxSearchSuggestions = 25
+	maxChoiceLength      = 100
+	queueTracksPerPage   = 5
+
+	// Lavalink accepts equalizer gains in this range, 0 leaves a band unchanged.
+	minEqualizerGain = -0.25
+	maxEqualizerGain = 1.0
+)
+
+// equalizerGroups maps the inputs of the equalizer modal to the lavalink bands
+// they control, their labels are looked up as music.equalizer.<id>.
+var equalizerGroups = []struct {
+	id    string
+	bands []int
+}{
+	{id: "bass", bands: []int{0, 1, 2, 3, 4}},
+	{id: "mids", bands: []int{5, 6, 7, 8, 9}},
+	{id: "treble", bands: []int{10, 11, 12, 13, 14}},
+}
+
 type MusicModule struct{}
 
 func (m *MusicModule) Name() string {
@@ -37,7 +62,14 @@ func (m *MusicModule) Register(r *registry.Registry) {
 		Options: []discord.ApplicationCommandOption{
 			discord.ApplicationCommandOptionString{Name: "query", Description: "Song name or URL", Required: true},
 		},
-		Execute: m.executePlay,
+		Checks:   []registry.CheckFunc{registry.SameVoiceChannel(), voiceChannelAllowed, botCanPlay, playerAvailable},
+		Cooldown: &registry.Cooldown{Per: 3 * time.Second, Burst: 2, Bucket: registry.BucketUser},
+		Contexts: guildContexts,
+		Autocomplete: map[string]registry.AutocompleteFunc{
+			"query": m.autocompleteQuery,
+		},
+		Examples: []string{"play never gonna give you up", "play https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		Execute:  m.executePlay,
 	})
 
 	r.Add(&registry.Command{
@@ -46,52 +78,156 @@ func (m *MusicModule) Register(r *registry.Registry) {
 		PrefixCommand: true,
 		SlashCommand:  true,
 		Aliases:       []string{"s", "next"},
+		Checks:        []registry.CheckFunc{registry.SameVoiceChannel(), playerAvailable},
+		Cooldown:      &registry.Cooldown{Per: 2 * time.Second, Burst: 3, Bucket: registry.BucketGuild},
+		Contexts:      guildContexts,
 		Execute:       m.executeSkip,
 	})
 
 	r.Add(&registry.Command{
 		Name:          "queue",
-		Description:   "Show the current music queue.",
+		Description:   "Manage the music queue.",
 		PrefixCommand: true,
 		SlashCommand:  true,
 		Aliases:       []string{"q"},
+		Checks:        []registry.CheckFunc{registry.GuildOnly(), playerAvailable},
+		Cooldown:      &registry.Cooldown{Per: 2 * time.Second, Burst: 2, Bucket: registry.BucketChannel},
+		Contexts:      guildContexts,
 		Execute:       m.executeQueue,
+		SubCommands: []*registry.Command{
+			{
+				Name:        "show",
+				Description: "Show the current music queue.",
+				Aliases:     []string{"list"},
+				Execute:     m.executeQueue,
+			},
+			{
+				Name:        "remove",
+				Description: "Remove a song from the queue.",
+				Aliases:     []string{"rm"},
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionInt{Name: "position", Description: "Position of the song in the queue", Required: true, MinValue: utils.Ptr(1)},
+				},
+				Autocomplete: map[string]registry.AutocompleteFunc{
+					"position": m.autocompleteQueuePosition,
+				},
+				Checks:   []registry.CheckFunc{registry.SameVoiceChannel()},
+				Examples: []string{"queue remove 3"},
+				Execute:  m.executeQueueRemove,
+			},
+			{
+				Name:        "clear",
+				Description: "Remove every song from the queue.",
+				Checks:      []registry.CheckFunc{registry.SameVoiceChannel()},
+				Confirm:     "Remove every song from the queue?",
+				Examples:    []string{"queue clear", "queue clear --yes"},
+				Execute:     m.executeQueueClear,
+			},
+			{
+				Name:        "shuffle",
+				Description: "Shuffle the songs in the queue.",
+				Checks:      []registry.CheckFunc{registry.SameVoiceChannel()},
+				Execute:     m.executeQueueShuffle,
+			},
+		},
+	})
+
+	r.Add(&registry.Command{
+		Name:         "equalizer",
+		Description:  "Adjust the bass, mids and treble of the player.",
+		SlashCommand: true,
+		Checks:       []registry.CheckFunc{registry.SameVoiceChannel(), playerAvailable},
+		Contexts:     guildContexts,
+		Execute:      m.executeEqualizer,
+	})
+
+	r.Add(&registry.Command{
+		Name:           "Add to queue",
+		MessageCommand: true,
+		Checks:         []registry.CheckFunc{registry.SameVoiceChannel(), voiceChannelAllowed, botCanPlay, playerAvailable},
+		Cooldown:       &registry.Cooldown{Per: 3 * time.Second, Burst: 2, Bucket: registry.BucketUser},
+		Contexts:       guildContexts,
+		Execute:        m.executeAddToQueue,
+	})
+
+	r.Add(&registry.Command{
+		Name:        "Show queued tracks",
+		UserCommand: true,
+		Checks:      []registry.CheckFunc{registry.GuildOnly(), playerAvailable},
+		Contexts:    guildContexts,
+		Execute:     m.executeQueuedBy,
+	})
+
+	r.AddModal(&registry.ModalHandler{
+		Prefix:  "equalizer",
+		Command: "equalizer",
+		Execute: m.submitEqualizer,
+	})
+
+	// The controls are sent with the song play starts, skip also has to pass
+	// the checks of the skip command.
+	r.AddComponent(&registry.ComponentHandler{
+		Prefix:  "music",
+		Command: "play",
+		Execute: m.handleControls,
 	})
 }
 
 func (m *MusicModule) executePlay(ctx *registry.Context) error {
-	guildID, err := getGuildID(ctx)
-	if err != nil {
-		return err
+	query, _ := ctx.GetStringOption("query")
+	if query == "" {
+		return &utils.UserError{Message: ctx.T("music.play.missing_query")}
 	}
 
-	voiceState, ok := ctx.Client().Caches().VoiceState(guildID, ctx.Author().ID)
-	if !ok || voiceState.ChannelID == nil {
-		return &utils.UserError{Message: "You need to be in a voice channel to use this command."}
-	}
+	return m.play(ctx, query)
+}
 
-	query := getQuery(ctx)
-	if query == "" {
-		return &utils.UserError{Message: "You need to specify a song. Ex: `!play <song>`"}
+// executeAddToQueue plays the first YouTube or Spotify link of the target
+// message.
+func (m *MusicModule) executeAddToQueue(ctx *registry.Context) error {
+	message, _ := ctx.TargetMessage()
+
+	link := trackLinkPattern.FindString(message.Content)
+	for _, embed := range message.Embeds {
+		if link != "" {
+			break
+		}
+		link = trackLinkPattern.FindString(embed.URL)
+	}
+	if link == "" {
+		return &utils.UserError{Message: ctx.T("music.add_to_queue.no_link")}
 	}
 
-	playerManager, err := getPlayerManager(ctx)
-	if err != nil {
-		return err
+	return m.play(ctx, link)
+}
+
+func (m *MusicModule) play(ctx *registry.Context, query string) error {
+	guildID := *ctx.GuildID()
+	channelID, _ := ctx.VoiceChannelID()
+
+	// Joining the channel and loading the track can take longer than the
+	// interaction deadline.
+	if err := ctx.Defer(false); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
 	}
 
+	playerManager := getPlayerManager(ctx)
+
 	userData := map[string]any{
 		"requesterId":   ctx.Author().ID.String(),
 		"requesterName": ctx.Author().Username,
 	}
 
-	track, position, err := playerManager.Play(context.Background(), ctx.Client(), guildID, *voiceState.ChannelID, query, userData)
+	track, position, err := playerManager.Play(context.Background(), ctx.Client(), guildID, channelID, query, userData)
+	if errors.Is(err, player.ErrNoResultsFound) || errors.Is(err, player.ErrNoTracksFound) || errors.Is(err, player.ErrNoTrackFound) {
+		return &utils.UserError{Kind: utils.KindNotFound, Message: ctx.T("music.play.no_results", query)}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to play song: %w", err)
 	}
 
-	embed := buildPlayEmbed(track, position, ctx.Author())
-	if err := ctx.SendEmbed(embed); err != nil {
+	embed := buildPlayEmbed(ctx, track, position)
+	if err := ctx.SendEmbed(embed, buildControls(ctx, playerManager.IsPaused(guildID))); err != nil {
 		return fmt.Errorf("failed to send embed: %w", err)
 	}
 
@@ -99,20 +235,14 @@ func (m *MusicModule) executePlay(ctx *registry.Context) error {
 }
 
 func (m *MusicModule) executeSkip(ctx *registry.Context) error {
-	guildID, err := getGuildID(ctx)
-	if err != nil {
-		return err
-	}
+	guildID := *ctx.GuildID()
 
-	playerManager, err := getPlayerManager(ctx)
-	if err != nil {
-		return err
-	}
+	playerManager := getPlayerManager(ctx)
 
 	track, err := playerManager.NextTrack(context.Background(), guildID)
 	if err != nil {
 		if errors.Is(err, player.ErrQueueEmpty) {
-			embed := buildSkipEmbed(nil)
+			embed := buildSkipEmbed(ctx, nil)
 			if sendErr := ctx.SendEmbed(embed); sendErr != nil {
 				return fmt.Errorf("failed to send embed: %w", sendErr)
 			}
@@ -121,7 +251,7 @@ func (m *MusicModule) executeSkip(ctx *registry.Context) error {
 		return fmt.Errorf("failed to skip song: %w", err)
 	}
 
-	embed := buildSkipEmbed(track)
+	embed := buildSkipEmbed(ctx, track)
 	if err := ctx.SendEmbed(embed); err != nil {
 		return fmt.Errorf("failed to send embed: %w", err)
 	}
@@ -129,69 +259,355 @@ func (m *MusicModule) executeSkip(ctx *registry.Context) error {
 	return nil
 }
 func (m *MusicModule) executeQueue(ctx *registry.Context) error {
-	guildID, err := getGuildID(ctx)
+	guildID := *ctx.GuildID()
+
+	playerManager := getPlayerManager(ctx)
+
+	queue, err := playerManager.GetQueue(context.Background(), guildID)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	nowPlayingTrack := playerManager.GetCurrentTrack(guildID)
+
+	if nowPlayingTrack == nil && (queue == nil || len(queue.Tracks) == 0) {
+		if err := ctx.Say(ctx.T("music.queue.empty")); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+
+		return nil
+	}
+
+	var tracks []lavalink.Track
+	if queue != nil {
+		tracks = queue.Tracks
 	}
+	position := playerManager.Position(guildID)
 
-	playerManager, err := getPlayerManager(ctx)
+	return ctx.Paginate(registry.Paginator{
+		Pages: (len(tracks) + queueTracksPerPage - 1) / queueTracksPerPage,
+		Page: func(page int) (discord.Embed, error) {
+			return buildQueueEmbed(ctx, nowPlayingTrack, tracks, position, page), nil
+		},
+	})
+}
+
+// executeQueuedBy lists the queued songs requested by the target member.
+func (m *MusicModule) executeQueuedBy(ctx *registry.Context) error {
+	target, _ := ctx.TargetUser()
+
+	queue, err := getPlayerManager(ctx).GetQueue(context.Background(), *ctx.GuildID())
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get queue: %w", err)
 	}
 
+	var sb strings.Builder
+	count := 0
+	if queue != nil {
+		for i, track := range queue.Tracks {
+			if getRequesterID(track) != target.ID.String() {
+				continue
+			}
+			count++
+			if count <= 10 {
+				sb.WriteString(fmt.Sprintf("`%d.` **%s** - `%s`\n", i+1, trackLink(track), utils.FormatDuration(int(track.Info.Length))))
+			}
+		}
+	}
+
+	if count == 0 {
+		return &utils.UserError{Kind: utils.KindNotFound, Message: ctx.T("music.queued_by.none", target.EffectiveName())}
+	}
+	if count > 10 {
+		sb.WriteString("*" + ctx.N("music.queue.more", count-10) + "*")
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(ctx.N("music.queued_by.title", count, target.EffectiveName())).
+		SetDescription(sb.String()).
+		SetThumbnail(target.EffectiveAvatarURL()).
+		SetColor(0x5865F2).
+		Build()
+
+	return ctx.Respond(registry.Response{Embeds: []discord.Embed{embed}, Ephemeral: true})
+}
+
+func (m *MusicModule) executeQueueRemove(ctx *registry.Context) error {
+	guildID := *ctx.GuildID()
+
+	playerManager := getPlayerManager(ctx)
+
+	position, _ := ctx.GetIntOption("position")
+
 	queue, err := playerManager.GetQueue(context.Background(), guildID)
 	if err != nil {
 		return fmt.Errorf("failed to get queue: %w", err)
 	}
 
-	player := playerManager.GetPlayer(guildID)
-	nowPlayingTrack := player.Track()
+	if queue == nil || position < 1 || int(position) > len(queue.Tracks) {
+		return &utils.UserError{Kind: utils.KindNotFound, Message: ctx.T("music.queue.remove.invalid_position")}
+	}
 
-	if nowPlayingTrack == nil && (queue == nil || len(queue.Tracks) == 0) {
-		if err := ctx.Say("The queue is empty."); err != nil {
-			return fmt.Errorf("failed to send message: %w", err)
+	track := queue.Tracks[position-1]
+	if err := playerManager.RemoveTrack(context.Background(), guildID, int(position-1)); err != nil {
+		return fmt.Errorf("failed to remove track: %w", err)
+	}
+
+	if err := ctx.Say(ctx.T("music.queue.remove.done", track.Info.Title)); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+func (m *MusicModule) executeQueueClear(ctx *registry.Context) error {
+	guildID := *ctx.GuildID()
+
+	playerManager := getPlayerManager(ctx)
+
+	if err := playerManager.ClearQueue(context.Background(), guildID); err != nil {
+		return fmt.Errorf("failed to clear queue: %w", err)
+	}
+
+	if err := ctx.Say(ctx.T("music.queue.clear.done")); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+func (m *MusicModule) executeQueueShuffle(ctx *registry.Context) error {
+	guildID := *ctx.GuildID()
+
+	playerManager := getPlayerManager(ctx)
+
+	if err := playerManager.ShuffleQueue(context.Background(), guildID); err != nil {
+		return fmt.Errorf("failed to shuffle queue: %w", err)
+	}
+
+	if err := ctx.Say(ctx.T("music.queue.shuffle.done")); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+func (m *MusicModule) executeEqualizer(ctx *registry.Context) error {
+	equalizer := getPlayerManager(ctx).GetEqualizer(*ctx.GuildID())
+
+	builder := discord.NewModalCreateBuilder().
+		SetCustomID(registry.CustomID("equalizer")).
+		SetTitle(ctx.T("music.equalizer.title"))
+
+	for _, group := range equalizerGroups {
+		builder.AddActionRow(discord.TextInputComponent{
+			CustomID:    group.id,
+			Style:       discord.TextInputStyleShort,
+			Label:       ctx.T("music.equalizer.label", ctx.T("music.equalizer."+group.id), minEqualizerGain, maxEqualizerGain),
+			MaxLength:   5,
+			Required:    true,
+			Placeholder: "0",
+			Value:       strconv.FormatFloat(float64(equalizer[group.bands[0]]), 'f', 2, 32),
+		})
+	}
+
+	return ctx.OpenModal(builder.Build())
+}
+
+func (m *MusicModule) submitEqualizer(ctx *registry.ModalContext) error {
+	guildID := *ctx.GuildID()
+	playerManager := getPlayerManager(ctx.Context)
+
+	equalizer := playerManager.GetEqualizer(guildID)
+	for _, group := range equalizerGroups {
+		gain, err := ctx.Float(group.id)
+		if err != nil {
+			return err
+		}
+		if gain < minEqualizerGain || gain > maxEqualizerGain {
+			return &utils.UserError{Message: ctx.T("music.equalizer.out_of_range", ctx.T("music.equalizer."+group.id), minEqualizerGain, maxEqualizerGain)}
 		}
 
-		return nil
+		for _, band := range group.bands {
+			equalizer[band] = float32(gain)
+		}
 	}
 
-	embed := buildQueueEmbed(ctx, nowPlayingTrack, queue, player.Position())
-	if err := ctx.SendEmbed(embed); err != nil {
-		return fmt.Errorf("failed to send embed: %w", err)
+	if err := playerManager.SetEqualizer(context.Background(), guildID, equalizer); err != nil {
+		return fmt.Errorf("failed to set equalizer: %w", err)
+	}
+
+	if err := ctx.Say(ctx.T("music.equalizer.done")); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
 	}
 
 	return nil
 }
 
-func buildPlayEmbed(track *lavalink.Track, position int, author discord.User) discord.Embed {
+func (m *MusicModule) handleControls(ctx *registry.ComponentContext) error {
+	guildID := *ctx.GuildID()
+	playerManager := getPlayerManager(ctx.Context)
+
+	switch ctx.Param(0) {
+	case "pause":
+		paused := !playerManager.IsPaused(guildID)
+		if err := playerManager.Pause(context.Background(), guildID, paused); err != nil {
+			return fmt.Errorf("failed to pause player: %w", err)
+		}
+
+		return ctx.UpdateMessage(discord.MessageUpdate{
+			Components: utils.Ptr([]discord.ContainerComponent{buildControls(ctx.Context, paused)}),
+		})
+
+	case "skip":
+		if err := ctx.CheckCommand("skip"); err != nil {
+			return err
+		}
+
+		track, err := playerManager.NextTrack(context.Background(), guildID)
+		if err != nil && !errors.Is(err, player.ErrQueueEmpty) {
+			return fmt.Errorf("failed to skip song: %w", err)
+		}
+
+		if err := ctx.SendEmbed(buildSkipEmbed(ctx.Context, track)); err != nil {
+			return fmt.Errorf("failed to send embed: %w", err)
+		}
+		return nil
+
+	case "stop":
+		if err := playerManager.ClearQueue(context.Background(), guildID); err != nil {
+			return fmt.Errorf("failed to clear queue: %w", err)
+		}
+		if err := playerManager.Stop(context.Background(), guildID); err != nil {
+			return fmt.Errorf("failed to stop player: %w", err)
+		}
+
+		return ctx.UpdateMessage(discord.MessageUpdate{
+			Components: utils.Ptr(registry.DisableComponents(ctx.Message().Components)),
+		})
+	}
+
+	return fmt.Errorf("unknown music control %q", ctx.Param(0))
+}
+
+func (m *MusicModule) autocompleteQuery(ctx *registry.AutocompleteContext) ([]discord.AutocompleteChoice, error) {
+	playerManager := autocompletePlayer(ctx)
+	query := strings.TrimSpace(ctx.Value())
+	if playerManager == nil || len(query) < 2 {
+		return nil, nil
+	}
+
+	searchCtx, cancel := context.WithTimeout(context.Background(), autocompleteTimeout)
+	defer cancel()
+
+	tracks, err := playerManager.Search(searchCtx, query)
+	if err != nil {
+		if errors.Is(err, player.ErrNoResultsFound) || errors.Is(err, player.ErrNoTracksFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to search tracks: %w", err)
+	}
+
+	choices := make([]discord.AutocompleteChoice, 0, min(len(tracks), maxSearchSuggestions))
+	for _, track := range tracks[:min(len(tracks), maxSearchSuggestions)] {
+		name := fmt.Sprintf("%s — %s (%s)", track.Info.Title, track.Info.Author, utils.FormatDuration(int(track.Info.Length)))
+
+		value := track.Info.Title + " " + track.Info.Author
+		if track.Info.URI != nil && len(*track.Info.URI) <= maxChoiceLength {
+			value = *track.Info.URI
+		}
+
+		choices = append(choices, discord.AutocompleteChoiceString{
+			Name:  utils.Truncate(name, maxChoiceLength),
+			Value: utils.Truncate(value, maxChoiceLength),
+		})
+	}
+
+	return choices, nil
+}
+
+func (m *MusicModule) autocompleteQueuePosition(ctx *registry.AutocompleteContext) ([]discord.AutocompleteChoice, error) {
+	playerManager := autocompletePlayer(ctx)
+	if playerManager == nil || ctx.GuildID() == nil {
+		return nil, nil
+	}
+
+	queueCtx, cancel := context.WithTimeout(context.Background(), autocompleteTimeout)
+	defer cancel()
+
+	queue, err := playerManager.GetQueue(queueCtx, *ctx.GuildID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	input := strings.ToLower(strings.TrimSpace(ctx.Value()))
+
+	var choices []discord.AutocompleteChoice
+	for i, track := range queue.Tracks {
+		position := strconv.Itoa(i + 1)
+		if input != "" && !strings.HasPrefix(position, input) && !strings.Contains(strings.ToLower(track.Info.Title), input) {
+			continue
+		}
+
+		choices = append(choices, discord.AutocompleteChoiceInt{
+			Name:  utils.Truncate(position+". "+track.Info.Title, maxChoiceLength),
+			Value: i + 1,
+		})
+		if len(choices) == maxSearchSuggestions {
+			break
+		}
+	}
+
+	return choices, nil
+}
+
+func buildPlayEmbed(ctx *registry.Context, track *lavalink.Track, position int) discord.Embed {
+	author := ctx.Author()
+
 	builder := discord.NewEmbedBuilder().
 		SetTitle(track.Info.Title).
-		SetURL(*track.Info.URI).
 		SetColor(0x2371AB).
-		AddField("Duration", utils.FormatDuration(int(track.Info.Length)), true).
+		AddField(ctx.T("music.embed.duration"), utils.FormatDuration(int(track.Info.Length)), true).
 		SetAuthor(track.Info.Author, "", "").
-		SetFooter("Requested by "+author.Username, author.EffectiveAvatarURL()).
+		SetFooter(ctx.T("music.embed.requested_by", author.Username), author.EffectiveAvatarURL()).
 		SetTimestamp(time.Now())
 
+	if track.Info.URI != nil {
+		builder.SetURL(*track.Info.URI)
+	}
+
 	if track.Info.ArtworkURL != nil {
 		builder.SetThumbnail(*track.Info.ArtworkURL)
 	}
 
 	if position > 0 {
-		builder.AddField("Queue Position", fmt.Sprint(position), true)
+		builder.AddField(ctx.T("music.embed.queue_position"), fmt.Sprint(position), true)
 	}
 
 	return builder.Build()
 }
 
-func buildSkipEmbed(track *lavalink.Track) discord.Embed {
+func buildControls(ctx *registry.Context, paused bool) discord.ContainerComponent {
+	pause := discord.NewSecondaryButton(ctx.T("music.controls.pause"), registry.CustomID("music", "pause"))
+	if paused {
+		pause = discord.NewSuccessButton(ctx.T("music.controls.resume"), registry.CustomID("music", "pause"))
+	}
+
+	return discord.NewActionRow(
+		pause,
+		discord.NewPrimaryButton(ctx.T("music.controls.skip"), registry.CustomID("music", "skip")),
+		discord.NewDangerButton(ctx.T("music.controls.stop"), registry.CustomID("music", "stop")),
+	)
+}
+
+func buildSkipEmbed(ctx *registry.Context, track *lavalink.Track) discord.Embed {
 	builder := discord.NewEmbedBuilder().SetColor(0x1DB954)
 
 	if track == nil {
-		builder.SetDescription("The queue has ended.")
+		builder.SetDescription(ctx.T("music.skip.queue_ended"))
 	} else {
-		builder.SetTitle("Song Skipped")
-		builder.SetDescription(fmt.Sprintf("Now playing: **[%s](%s)**", track.Info.Title, *track.Info.URI))
+		builder.SetTitle(ctx.T("music.skip.title"))
+		builder.SetDescription(ctx.T("music.skip.now_playing", trackLink(*track)))
 		if track.Info.ArtworkURL != nil {
 			builder.SetThumbnail(*track.Info.ArtworkURL)
 		}
@@ -200,11 +616,13 @@ func buildSkipEmbed(track *lavalink.Track) discord.Embed {
 	return builder.Build()
 }
 
-func buildQueueEmbed(ctx *registry.Context, nowPlaying *lavalink.Track, queue *player.Queue, position lavalink.Duration) discord.Embed {
+// buildQueueEmbed shows the current track and one page of the tracks queued
+// after it.
+func buildQueueEmbed(ctx *registry.Context, nowPlaying *lavalink.Track, tracks []lavalink.Track, position lavalink.Duration, page int) discord.Embed {
 	embed := discord.NewEmbedBuilder().
 		SetColor(0x5865F2).
 		SetTimestamp(time.Now()).
-		SetFooter("Requested by "+ctx.Author().Username, ctx.Author().EffectiveAvatarURL())
+		SetFooter(ctx.T("music.embed.requested_by", ctx.Author().Username), ctx.Author().EffectiveAvatarURL())
 
 	if nowPlaying != nil {
 		if nowPlaying.Info.ArtworkURL != nil {
@@ -214,74 +632,84 @@ func buildQueueEmbed(ctx *registry.Context, nowPlaying *lavalink.Track, queue *p
 		duration := utils.FormatDuration(int(nowPlaying.Info.Length))
 		currentPosition := utils.FormatDuration(int(position))
 
-		trackInfo := fmt.Sprintf("**[%s](%s)** - `%s` / `%s`",
-			nowPlaying.Info.Title, *nowPlaying.Info.URI, currentPosition, duration)
+		trackInfo := fmt.Sprintf("**%s** - `%s` / `%s`", trackLink(*nowPlaying), currentPosition, duration)
 
 		if reqID := getRequesterID(*nowPlaying); reqID != "" {
-			trackInfo += fmt.Sprintf("\n- Requested by <@%s>", reqID)
+			trackInfo += "\n- " + ctx.T("music.embed.requested_by", "<@"+reqID+">")
 		}
 
-		embed.AddField("▶ Now Playing", trackInfo, false)
+		embed.AddField(ctx.T("music.queue.now_playing"), trackInfo, false)
 	}
 
-	if queue != nil && len(queue.Tracks) > 0 {
+	if len(tracks) > 0 {
 		var (
 			sb            strings.Builder
 			totalDuration lavalink.Duration
 		)
 
-		for i, track := range queue.Tracks {
+		start := page * queueTracksPerPage
+		end := min(start+queueTracksPerPage, len(tracks))
+		for i, track := range tracks {
 			totalDuration += track.Info.Length
-			if i < 5 {
+			if i >= start && i < end {
 				duration := utils.FormatDuration(int(track.Info.Length))
-				sb.WriteString(fmt.Sprintf("`%d.` **[%s](%s)** - `%s`", i+1, track.Info.Title, *track.Info.URI, duration))
+				sb.WriteString(fmt.Sprintf("`%d.` **%s** - `%s`", i+1, trackLink(track), duration))
 
 				if reqID := getRequesterID(track); reqID != "" {
-					sb.WriteString(fmt.Sprintf("\n- Requested by <@%s>", reqID))
+					sb.WriteString("\n- " + ctx.T("music.embed.requested_by", "<@"+reqID+">"))
 				}
 				sb.WriteString("\n")
 			}
 		}
 
-		if len(queue.Tracks) > 5 {
-			sb.WriteString(fmt.Sprintf("*...and %d more song(s)*", len(queue.Tracks)-5))
-		}
-
-		embed.AddField(fmt.Sprintf("Up Next (%d)", len(queue.Tracks)), sb.String(), true)
-		embed.AddField("Duration", utils.FormatDuration(int(totalDuration)), true)
+		embed.AddField(ctx.N("music.queue.up_next", len(tracks)), sb.String(), true)
+		embed.AddField(ctx.T("music.embed.duration"), utils.FormatDuration(int(totalDuration)), true)
 	}
 
 	return embed.Build()
 }
 
-func getGuildID(ctx *registry.Context) (snowflake.ID, error) {
-	if ctx.GuildID() == nil {
-		return 0, &utils.UserError{Message: "This command can only be used in a server."}
+// trackLink is the track's title as a Markdown link to it, or just the title
+// for tracks without a URI, like some streams and local files.
+func trackLink(track lavalink.Track) string {
+	if track.Info.URI == nil {
+		return track.Info.Title
 	}
-
-	return *ctx.GuildID(), nil
+	return "[" + track.Info.Title + "](" + *track.Info.URI + ")"
 }
 
-func getPlayerManager(ctx *registry.Context) (*player.Player, error) {
+// botCanPlay makes sure the bot can join the author's voice channel and be
+// heard in it, which otherwise fails without an error.
+var botCanPlay = registry.BotHasVoicePermission(discord.PermissionConnect | discord.PermissionSpeak)
+
+// playerAvailable requires the music player, which is missing until Lavalink
+// connects and for good when it can't. It stays here rather than beside
+// registry.InVoiceChannel since the registry only sees Context.Data as any and
+// knows nothing of types.BotData or the player.
+func playerAvailable(ctx *registry.Context) error {
 	botData, ok := ctx.Data().(*types.BotData)
 	if !ok {
-		return nil, ErrInvalidBotData
+		return ErrInvalidBotData
 	}
 
 	if botData.Player == nil {
-		return nil, &utils.UserError{Message: "The music player is not available."}
+		return &utils.UserError{Kind: utils.KindUnavailable, Message: ctx.T("music.unavailable")}
 	}
 
-	return botData.Player, nil
+	return nil
 }
 
-func getQuery(ctx *registry.Context) string {
-	if ctx.IsSlash() {
-		query, _ := ctx.GetStringOption("query")
-		return query
+func autocompletePlayer(ctx *registry.AutocompleteContext) player.Manager {
+	botData, ok := ctx.Data().(*types.BotData)
+	if !ok {
+		return nil
 	}
+	return botData.Player
+}
 
-	return strings.Join(ctx.Args(), " ")
+// getPlayerManager must only be called from commands guarded by playerAvailable.
+func getPlayerManager(ctx *registry.Context) player.Manager {
+	return ctx.Data().(*types.BotData).Player
 }
 
 func getRequesterID(track lavalink.Track) string {
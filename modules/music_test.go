@@ -0,0 +1,259 @@
+package modules_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+
+	"github.com/goland-express/flexo/player/playertest"
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/registry/registrytest"
+	"github.com/goland-express/flexo/utils"
+)
+
+func TestPlay(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	b.Player.Tracks["rick"] = []lavalink.Track{playertest.Track("Never Gonna Give You Up", 213_000)}
+	b.Player.Tracks["nothing"] = nil
+
+	for _, invoke := range []func(query string) *registrytest.Result{
+		func(query string) *registrytest.Result { return b.Prefix("!play " + query) },
+		func(query string) *registrytest.Result { return b.Slash("play", map[string]any{"query": query}) },
+	} {
+		response := b.run(t, invoke("rick")).Last()
+		if len(response.Embeds) != 1 || !strings.Contains(embedText(response), "Never Gonna Give You Up") {
+			t.Errorf("got %+v, want an embed naming the track", response)
+		}
+		if len(registrytest.Buttons(response)) != 3 {
+			t.Errorf("got %d buttons, want the pause, skip and stop controls", len(registrytest.Buttons(response)))
+		}
+
+		result := invoke("nothing")
+		if kind := utils.KindOf(result.Err); kind != utils.KindNotFound {
+			t.Errorf("got error kind %s, want not found: %v", kind, result.Err)
+		}
+	}
+
+	guild := b.Player.Guild(*b.GuildID)
+	if guild.ChannelID != voiceChannelID || guild.Current == nil || len(guild.Queue) != 1 {
+		t.Errorf("got channel %s, current %v and %d queued, want one playing and one queued in %s",
+			guild.ChannelID, guild.Current, len(guild.Queue), voiceChannelID)
+	}
+}
+
+func TestTrackWithoutURI(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	radio := playertest.Track("Lofi Radio", 0)
+	radio.Info.URI = nil
+	b.Player.Tracks["radio"] = []lavalink.Track{radio}
+
+	for i := range 3 {
+		if response := b.run(t, b.Prefix("!play radio")).Last(); response.Embeds[0].URL != "" {
+			t.Errorf("play %d links the embed to %q", i, response.Embeds[0].URL)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		invoke func() *registrytest.Result
+		want   string
+	}{
+		{
+			name:   "queue",
+			invoke: func() *registrytest.Result { return b.Prefix("!queue") },
+			want:   "`1.` **Lofi Radio** - ",
+		},
+		{
+			name:   "queued by",
+			invoke: func() *registrytest.Result { return b.UserCommand("Show queued tracks", b.Author) },
+			want:   "`2.` **Lofi Radio** - ",
+		},
+		{
+			name:   "skip",
+			invoke: func() *registrytest.Result { return b.Prefix("!skip") },
+			want:   "Now playing: **Lofi Radio**",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := embedText(b.run(t, tt.invoke()).Last())
+			if !strings.Contains(text, tt.want) || strings.Contains(text, "](") {
+				t.Errorf("got:\n%s\nwant %q without a link", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayOutsideVoice(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	b.LeaveVoice(b.Author.ID)
+
+	result := b.Prefix("!play song")
+	if kind := utils.KindOf(result.Err); kind != utils.KindForbidden {
+		t.Fatalf("got error kind %s, want forbidden: %v", kind, result.Err)
+	}
+	if current := b.Player.Guild(*b.GuildID).Current; current != nil {
+		t.Errorf("got %q playing, want nothing", current.Info.Title)
+	}
+}
+
+func TestQueuePages(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	queueSongs(t, b, 12)
+
+	first := b.run(t, b.Prefix("!queue")).Last()
+	if text := embedText(first); !strings.Contains(text, "`1.` **[Song 1]") || strings.Contains(text, "Song 6]") {
+		t.Fatalf("first page shows:\n%s", text)
+	}
+	if text := embedText(first); !strings.Contains(text, "Up Next (11 songs)") {
+		t.Errorf("first page doesn't count 11 songs:\n%s", text)
+	}
+
+	next := paginatorButton(t, first, "next")
+	second := b.run(t, b.Click(first, next)).Last()
+	if !second.Edit {
+		t.Error("turning the page sent a new message instead of editing")
+	}
+	if text := embedText(second); !strings.Contains(text, "`6.` **[Song 6]") || strings.Contains(text, "Song 5]") {
+		t.Errorf("second page shows:\n%s", text)
+	}
+	if label := registrytest.Buttons(second)[2].Label; label != "2 / 3" {
+		t.Errorf("got page label %q, want 2 / 3", label)
+	}
+
+	last := b.run(t, b.Click(second, paginatorButton(t, second, "last"))).Last()
+	if text := embedText(last); !strings.Contains(text, "`11.` **[Song 11]") {
+		t.Errorf("last page shows:\n%s", text)
+	}
+	for _, button := range registrytest.Buttons(last)[3:] {
+		if !button.Disabled {
+			t.Errorf("%s is enabled on the last page", button.Label)
+		}
+	}
+
+	jump := b.run(t, b.Slash("queue show", nil))
+	if jump.Last().Modal != nil {
+		t.Fatal("queue show opened a modal")
+	}
+	modal := b.run(t, b.Click(jump.Last(), paginatorButton(t, jump.Last(), "jump"))).Last().Modal
+	if modal == nil {
+		t.Fatal("the page button didn't open a modal")
+	}
+	jumped := b.run(t, b.Submit(modal.CustomID, map[string]string{"page": "3"})).Last()
+	if text := embedText(jumped); !strings.Contains(text, "Song 11]") {
+		t.Errorf("jumping to page 3 shows:\n%s", text)
+	}
+}
+
+func TestQueuedBy(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	queueSongs(t, b, 2)
+
+	text := embedText(b.run(t, b.UserCommand("Show queued tracks", b.Author)).Last())
+	if !strings.Contains(text, "Queued by tester (1 song)") || !strings.Contains(text, "`1.` **[Song 1]") {
+		t.Errorf("got:\n%s\nwant the one queued song counted", text)
+	}
+	if text := embedText(b.run(t, b.Prefix("!queue")).Last()); !strings.Contains(text, "Up Next (1 song)") {
+		t.Errorf("got:\n%s\nwant one song up next", text)
+	}
+}
+
+func TestQueueRemove(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	queueSongs(t, b, 5)
+
+	response := b.run(t, b.Prefix("!queue remove 2")).Last()
+	if want := "Removed **Song 2** from the queue."; response.Content != want {
+		t.Errorf("got %q, want %q", response.Content, want)
+	}
+	b.run(t, b.Slash("queue remove", map[string]any{"position": 3}))
+
+	var titles []string
+	for _, track := range b.Player.Guild(*b.GuildID).Queue {
+		titles = append(titles, track.Info.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Song 1, Song 3" {
+		t.Errorf("got queue %s, want Song 1, Song 3", got)
+	}
+
+	for _, command := range []string{"!queue remove 9", "!queue remove 0"} {
+		if kind := utils.KindOf(b.Prefix(command).Err); kind != utils.KindNotFound && kind != utils.KindBadInput {
+			t.Errorf("%s: got error kind %s, want not found or bad input", command, kind)
+		}
+	}
+}
+
+func TestQueueClearConfirmation(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		command string
+		cleared bool
+		reply   string
+	}{
+		{name: "yes", answer: "yes", command: "!queue clear", cleared: true, reply: "The queue has been cleared."},
+		{name: "no", answer: "no", command: "!queue clear", reply: "Cancelled, nothing was changed."},
+		{name: "timeout", command: "!queue clear", reply: "No answer in time, nothing was changed."},
+		{name: "flag", command: "!queue clear --yes", cleared: true, reply: "The queue has been cleared."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, registry.Options{ConfirmTimeout: 20 * time.Millisecond})
+			queueSongs(t, b, 3)
+			b.Answer = tt.answer
+
+			result := b.run(t, b.Prefix(tt.command))
+			if got := result.Last().Content; got != tt.reply {
+				t.Errorf("got %q, want %q", got, tt.reply)
+			}
+			if cleared := len(b.Player.Guild(*b.GuildID).Queue) == 0; cleared != tt.cleared {
+				t.Errorf("got cleared %v, want %v", cleared, tt.cleared)
+			}
+		})
+	}
+}
+
+func TestQueueClearSlashForce(t *testing.T) {
+	b := newTestBot(t, registry.Options{ConfirmTimeout: time.Hour})
+	queueSongs(t, b, 3)
+
+	b.run(t, b.Slash("queue clear", map[string]any{"force": true}))
+	if queue := b.Player.Guild(*b.GuildID).Queue; len(queue) != 0 {
+		t.Errorf("got %d songs left, want the queue cleared without asking", len(queue))
+	}
+}
+
+// queueSongs starts playing Song 0 and queues Song 1 to Song n-1.
+func queueSongs(t *testing.T, b *testBot, n int) {
+	t.Helper()
+	for i := range n {
+		b.run(t, b.Prefix(fmt.Sprintf("!play Song %d", i)))
+	}
+}
+
+func paginatorButton(t *testing.T, response registrytest.Response, action string) string {
+	t.Helper()
+	for _, button := range registrytest.Buttons(response) {
+		if strings.HasPrefix(button.CustomID, "paginator:") && strings.HasSuffix(button.CustomID, ":"+action) {
+			return button.CustomID
+		}
+	}
+	t.Fatalf("no %s button in %+v", action, response.Components)
+	return ""
+}
+
+func embedText(response registrytest.Response) string {
+	var sb strings.Builder
+	for _, embed := range response.Embeds {
+		sb.WriteString(embed.Title + "\n" + embed.Description + "\n")
+		for _, field := range embed.Fields {
+			sb.WriteString(field.Name + "\n" + field.Value + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,490 @@
+package modules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/settings"
+	"github.com/goland-express/flexo/types"
+	"github.com/goland-express/flexo/utils"
+)
+
+// policyCommand is never restricted so admins can't lock themselves out.
+const policyCommand = "policy"
+
+var (
+	textChannelTypes  = []discord.ChannelType{discord.ChannelTypeGuildText, discord.ChannelTypeGuildNews, discord.ChannelTypeGuildVoice}
+	voiceChannelTypes = []discord.ChannelType{discord.ChannelTypeGuildVoice, discord.ChannelTypeGuildStageVoice}
+)
+
+func (m *SettingsModule) registerPolicy(r *registry.Registry) {
+	target := discord.ApplicationCommandOptionString{Name: "target", Description: "Command (like queue shuffle) or module (like Music)", Required: true}
+	autocompleteTarget := map[string]registry.AutocompleteFunc{"target": m.autocompletePolicyTarget}
+
+	ruleOptions := []discord.ApplicationCommandOption{
+		target,
+		discord.ApplicationCommandOptionChannel{Name: "channel", Description: "Text channel", ChannelTypes: textChannelTypes},
+		discord.ApplicationCommandOptionRole{Name: "role", Description: "Role"},
+	}
+
+	r.Add(&registry.Command{
+		Name:          policyCommand,
+		Description:   "Restrict where and by whom commands can be used in this server.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		Checks: []registry.CheckFunc{
+			registry.GuildOnly(),
+			settingsAvailable,
+			registry.HasPermission(discord.PermissionManageGuild),
+		},
+		DefaultMemberPermissions: discord.PermissionManageGuild,
+		Contexts:                 guildContexts,
+		Execute:                  m.executePolicyShow,
+		SubCommands: []*registry.Command{
+			{
+				Name:         "show",
+				Description:  "Show the restrictions of this server.",
+				Aliases:      []string{"list"},
+				Options:      []discord.ApplicationCommandOption{discord.ApplicationCommandOptionString{Name: "target", Description: target.Description}},
+				Autocomplete: autocompleteTarget,
+				Execute:      m.executePolicyShow,
+			},
+			{
+				Name:         "disable",
+				Description:  "Disable a command or module in this server.",
+				Options:      []discord.ApplicationCommandOption{target},
+				Autocomplete: autocompleteTarget,
+				Examples:     []string{`policy disable "queue shuffle"`, "policy disable Music"},
+				Execute:      m.executePolicyDisable,
+			},
+			{
+				Name:         "enable",
+				Description:  "Enable a disabled command or module again.",
+				Options:      []discord.ApplicationCommandOption{target},
+				Autocomplete: autocompleteTarget,
+				Examples:     []string{`policy enable "queue shuffle"`},
+				Execute:      m.executePolicyEnable,
+			},
+			{
+				Name:         "allow",
+				Description:  "Only allow a command or module in a channel or for a role.",
+				Options:      ruleOptions,
+				Autocomplete: autocompleteTarget,
+				Examples:     []string{"policy allow Music #music", "policy allow play @DJ"},
+				Execute:      m.executePolicyAllow,
+			},
+			{
+				Name:         "deny",
+				Description:  "Block a command or module in a channel or for a role.",
+				Options:      ruleOptions,
+				Autocomplete: autocompleteTarget,
+				Examples:     []string{"policy deny Music #general", "policy deny skip @Muted"},
+				Execute:      m.executePolicyDeny,
+			},
+			{
+				Name:         "reset",
+				Description:  "Remove every restriction of a command or module.",
+				Options:      []discord.ApplicationCommandOption{target},
+				Autocomplete: autocompleteTarget,
+				Confirm:      "Remove every restriction of this command or module?",
+				Examples:     []string{"policy reset Music --yes"},
+				Execute:      m.executePolicyReset,
+			},
+			{
+				Name:        "voice",
+				Description: "Choose the voice channels the bot may join.",
+				SubCommands: []*registry.Command{
+					{
+						Name:        "add",
+						Description: "Allow the bot to join a voice channel.",
+						Options: []discord.ApplicationCommandOption{
+							discord.ApplicationCommandOptionChannel{Name: "channel", Description: "Voice channel", Required: true, ChannelTypes: voiceChannelTypes},
+						},
+						Execute: m.executePolicyVoiceAdd,
+					},
+					{
+						Name:        "remove",
+						Description: "Stop allowing the bot to join a voice channel.",
+						Aliases:     []string{"rm"},
+						Options: []discord.ApplicationCommandOption{
+							discord.ApplicationCommandOptionChannel{Name: "channel", Description: "Voice channel", Required: true, ChannelTypes: voiceChannelTypes},
+						},
+						Execute: m.executePolicyVoiceRemove,
+					},
+					{
+						Name:        "clear",
+						Description: "Let the bot join any voice channel.",
+						Confirm:     "Let the bot join any voice channel again?",
+						Execute:     m.executePolicyVoiceClear,
+					},
+				},
+			},
+		},
+	})
+}
+
+// CheckPolicy rejects commands restricted in the guild, it's meant to be one
+// of the registry's checks. Threads follow the rules of their parent channel.
+func (m *SettingsModule) CheckPolicy(ctx *registry.Context) error {
+	guildID := ctx.GuildID()
+	path := ctx.Path()
+	botData, ok := ctx.Data().(*types.BotData)
+	if guildID == nil || len(path) == 0 || path[0].Name == policyCommand || !ok || botData.Settings == nil {
+		return nil
+	}
+
+	guild := botData.Settings.Guild(*guildID)
+	if len(guild.Commands) == 0 && len(guild.Modules) == 0 {
+		return nil
+	}
+
+	var roleIDs []snowflake.ID
+	if member := ctx.Member(); member != nil {
+		roleIDs = member.RoleIDs
+	}
+	channelID := ctx.ParentChannelID()
+
+	if rule, ok := guild.Modules[path[0].Module]; ok {
+		if err := policyError(ctx, moduleSubject(ctx, path[0].Module), channelID, rule, rule.Check(channelID, roleIDs)); err != nil {
+			return err
+		}
+	}
+
+	for i := range path {
+		key := commandName(path[:i+1])
+		if rule, ok := guild.Commands[key]; ok {
+			if err := policyError(ctx, ctx.T("policy.subject.command", key), channelID, rule, rule.Check(channelID, roleIDs)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func policyError(ctx *registry.Context, subject string, channelID snowflake.ID, rule settings.Rule, verdict settings.Verdict) error {
+	switch verdict {
+	case settings.DeniedDisabled:
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("policy.denied.disabled", subject)}
+	case settings.DeniedChannel:
+		if len(rule.AllowedChannels) > 0 && !slices.Contains(rule.DeniedChannels, channelID) {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("policy.denied.channel_only", subject, mentions("<#%s>", rule.AllowedChannels))}
+		}
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("policy.denied.channel", subject)}
+	case settings.DeniedRole:
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("policy.denied.role", subject)}
+	}
+	return nil
+}
+
+// voiceChannelAllowed keeps the bot out of voice channels the guild didn't
+// allow.
+func voiceChannelAllowed(ctx *registry.Context) error {
+	guildID := ctx.GuildID()
+	botData, ok := ctx.Data().(*types.BotData)
+	if guildID == nil || !ok || botData.Settings == nil {
+		return nil
+	}
+
+	allowed := botData.Settings.Guild(*guildID).VoiceChannels
+	channelID, ok := ctx.VoiceChannelID()
+	if !ok || len(allowed) == 0 || slices.Contains(allowed, channelID) {
+		return nil
+	}
+	return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("policy.voice.denied", mentions("<#%s>", allowed))}
+}
+
+func (m *SettingsModule) executePolicyShow(ctx *registry.Context) error {
+	guild := getSettings(ctx).Guild(*ctx.GuildID())
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(ctx.T("policy.show.title")).
+		SetColor(0x5865F2)
+
+	if input, ok := ctx.GetStringOption("target"); ok && input != "" {
+		key, isModule, err := m.resolvePolicyTarget(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		rule, subject := guild.Commands[key], ctx.T("policy.subject.command", key)
+		if isModule {
+			rule, subject = guild.Modules[key], moduleSubject(ctx, key)
+		}
+		embed.SetDescription(subject + "\n" + describeRule(ctx, rule))
+	} else {
+		describePolicy(ctx, embed, guild)
+	}
+
+	if err := ctx.SendEmbed(embed.Build()); err != nil {
+		return fmt.Errorf("failed to send embed: %w", err)
+	}
+	return nil
+}
+
+// describePolicy adds every rule and the allowed voice channels to the embed.
+func describePolicy(ctx *registry.Context, embed *discord.EmbedBuilder, guild settings.Guild) {
+	for _, module := range sortedKeys(guild.Modules) {
+		embed.AddField(moduleSubject(ctx, module), describeRule(ctx, guild.Modules[module]), false)
+	}
+	for _, command := range sortedKeys(guild.Commands) {
+		embed.AddField(ctx.T("policy.subject.command", command), describeRule(ctx, guild.Commands[command]), false)
+	}
+
+	voiceChannels := ctx.T("policy.voice.any")
+	if len(guild.VoiceChannels) > 0 {
+		voiceChannels = mentions("<#%s>", guild.VoiceChannels)
+	}
+	embed.AddField(ctx.T("policy.show.voice_channels"), voiceChannels, false)
+
+	if len(guild.Modules) == 0 && len(guild.Commands) == 0 {
+		embed.SetDescription(ctx.T("policy.show.empty"))
+	}
+}
+
+func (m *SettingsModule) executePolicyDisable(ctx *registry.Context) error {
+	return m.updateRule(ctx, func(rule *settings.Rule) {
+		rule.Disabled = true
+	})
+}
+
+func (m *SettingsModule) executePolicyEnable(ctx *registry.Context) error {
+	return m.updateRule(ctx, func(rule *settings.Rule) {
+		rule.Disabled = false
+	})
+}
+
+func (m *SettingsModule) executePolicyAllow(ctx *registry.Context) error {
+	channelID, roleID, err := channelOrRole(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.updateRule(ctx, func(rule *settings.Rule) {
+		moveID(channelID, &rule.AllowedChannels, &rule.DeniedChannels)
+		moveID(roleID, &rule.AllowedRoles, &rule.DeniedRoles)
+	})
+}
+
+func (m *SettingsModule) executePolicyDeny(ctx *registry.Context) error {
+	channelID, roleID, err := channelOrRole(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.updateRule(ctx, func(rule *settings.Rule) {
+		moveID(channelID, &rule.DeniedChannels, &rule.AllowedChannels)
+		moveID(roleID, &rule.DeniedRoles, &rule.AllowedRoles)
+	})
+}
+
+func (m *SettingsModule) executePolicyReset(ctx *registry.Context) error {
+	return m.updateRule(ctx, func(rule *settings.Rule) {
+		*rule = settings.Rule{}
+	})
+}
+
+// channelOrRole returns the channel and role options, zero when not given.
+// At least one of them is required.
+func channelOrRole(ctx *registry.Context) (snowflake.ID, snowflake.ID, error) {
+	channelID, hasChannel := ctx.GetChannelOption("channel")
+	roleID, hasRole := ctx.GetRoleOption("role")
+	if !hasChannel && !hasRole {
+		return 0, 0, &utils.UserError{Message: ctx.T("policy.missing_channel_or_role")}
+	}
+	return channelID, roleID, nil
+}
+
+// moveID adds id to the list and removes it from the opposite one, a zero ID
+// is ignored.
+func moveID(id snowflake.ID, list, opposite *[]snowflake.ID) {
+	if id == 0 {
+		return
+	}
+	*list = appendUnique(*list, id)
+	*opposite = slices.DeleteFunc(*opposite, func(other snowflake.ID) bool { return other == id })
+}
+
+// updateRule applies update to the rule of the target option and shows the
+// result. Rules that no longer restrict anything are dropped.
+func (m *SettingsModule) updateRule(ctx *registry.Context, update func(rule *settings.Rule)) error {
+	input, _ := ctx.GetStringOption("target")
+	key, isModule, err := m.resolvePolicyTarget(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	var rule settings.Rule
+	err = getSettings(ctx).Update(*ctx.GuildID(), func(g *settings.Guild) {
+		rules := &g.Commands
+		if isModule {
+			rules = &g.Modules
+		}
+
+		rule = (*rules)[key]
+		update(&rule)
+
+		if rule.IsZero() {
+			delete(*rules, key)
+			return
+		}
+		if *rules == nil {
+			*rules = make(map[string]settings.Rule)
+		}
+		(*rules)[key] = rule
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save policy: %w", err)
+	}
+
+	subject := ctx.T("policy.subject.command", key)
+	if isModule {
+		subject = moduleSubject(ctx, key)
+	}
+	if err := ctx.Say(ctx.T("policy.updated", subject, describeRule(ctx, rule))); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func (m *SettingsModule) executePolicyVoiceAdd(ctx *registry.Context) error {
+	channelID, _ := ctx.GetChannelOption("channel")
+	return m.updateVoiceChannels(ctx, func(channels []snowflake.ID) []snowflake.ID {
+		return appendUnique(channels, channelID)
+	})
+}
+
+func (m *SettingsModule) executePolicyVoiceRemove(ctx *registry.Context) error {
+	channelID, _ := ctx.GetChannelOption("channel")
+	return m.updateVoiceChannels(ctx, func(channels []snowflake.ID) []snowflake.ID {
+		return slices.DeleteFunc(channels, func(id snowflake.ID) bool { return id == channelID })
+	})
+}
+
+func (m *SettingsModule) executePolicyVoiceClear(ctx *registry.Context) error {
+	return m.updateVoiceChannels(ctx, func([]snowflake.ID) []snowflake.ID {
+		return nil
+	})
+}
+
+func (m *SettingsModule) updateVoiceChannels(ctx *registry.Context, update func(channels []snowflake.ID) []snowflake.ID) error {
+	var channels []snowflake.ID
+	err := getSettings(ctx).Update(*ctx.GuildID(), func(g *settings.Guild) {
+		g.VoiceChannels = update(g.VoiceChannels)
+		channels = g.VoiceChannels
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save voice channels: %w", err)
+	}
+
+	message := ctx.T("policy.voice.any")
+	if len(channels) > 0 {
+		message = ctx.T("policy.voice.only", mentions("<#%s>", channels))
+	}
+	if err := ctx.Say(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// resolvePolicyTarget turns user input into the key of a module or command
+// rule, reporting which one it is.
+func (m *SettingsModule) resolvePolicyTarget(ctx *registry.Context, input string) (string, bool, error) {
+	input = strings.TrimSpace(input)
+	for _, module := range m.registry.Modules() {
+		if strings.EqualFold(module, input) {
+			return module, true, nil
+		}
+	}
+
+	path := m.registry.Lookup(input)
+	if path == nil {
+		return "", false, &utils.UserError{Kind: utils.KindNotFound, Message: ctx.T("policy.unknown_target", input)}
+	}
+	if path[0].Name == policyCommand {
+		return "", false, &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("policy.protected", policyCommand)}
+	}
+	return commandName(path), false, nil
+}
+
+func (m *SettingsModule) autocompletePolicyTarget(ctx *registry.AutocompleteContext) ([]discord.AutocompleteChoice, error) {
+	input := strings.ToLower(strings.TrimSpace(ctx.Value()))
+
+	var targets []string
+	targets = append(targets, m.registry.Modules()...)
+	for _, cmd := range m.registry.Commands() {
+		if cmd.Name == policyCommand {
+			continue
+		}
+		targets = append(targets, cmd.Name)
+		for _, sub := range cmd.SubCommands {
+			targets = append(targets, cmd.Name+" "+sub.Name)
+		}
+	}
+
+	var choices []discord.AutocompleteChoice
+	for _, target := range targets {
+		if strings.Contains(strings.ToLower(target), input) {
+			choices = append(choices, discord.AutocompleteChoiceString{Name: target, Value: target})
+		}
+	}
+	return choices, nil
+}
+
+func describeRule(ctx *registry.Context, rule settings.Rule) string {
+	if rule.IsZero() {
+		return ctx.T("policy.rule.none")
+	}
+
+	var lines []string
+	if rule.Disabled {
+		lines = append(lines, ctx.T("policy.rule.disabled"))
+	}
+	for _, list := range []struct {
+		key string
+		ids []snowflake.ID
+		fmt string
+	}{
+		{"policy.rule.allowed_channels", rule.AllowedChannels, "<#%s>"},
+		{"policy.rule.denied_channels", rule.DeniedChannels, "<#%s>"},
+		{"policy.rule.allowed_roles", rule.AllowedRoles, "<@&%s>"},
+		{"policy.rule.denied_roles", rule.DeniedRoles, "<@&%s>"},
+	} {
+		if len(list.ids) > 0 {
+			lines = append(lines, ctx.T(list.key, mentions(list.fmt, list.ids)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func moduleSubject(ctx *registry.Context, module string) string {
+	return ctx.T("policy.subject.module", ctx.Localize("modules."+module, module))
+}
+
+func mentions(format string, ids []snowflake.ID) string {
+	formatted := make([]string, len(ids))
+	for i, id := range ids {
+		formatted[i] = fmt.Sprintf(format, id)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func appendUnique(ids []snowflake.ID, id snowflake.ID) []snowflake.ID {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
+
+func sortedKeys(rules map[string]settings.Rule) []string {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
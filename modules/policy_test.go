@@ -0,0 +1,93 @@
+package modules_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/registry/registrytest"
+	"github.com/goland-express/flexo/utils"
+)
+
+func TestPolicyCoversComponentsAndModals(t *testing.T) {
+	tests := []struct {
+		name    string
+		disable string
+		denied  []string
+		allowed []string
+	}{
+		{name: "module", disable: "Music", denied: []string{"music:pause", "music:skip", "music:stop"}},
+		{name: "owning command", disable: "play", denied: []string{"music:pause", "music:skip"}},
+		{name: "skip only", disable: "skip", denied: []string{"music:skip"}, allowed: []string{"music:pause"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, registry.Options{})
+			nowPlaying := b.run(t, b.Prefix("!play first song")).Last()
+			b.run(t, b.Prefix("!play second song"))
+			b.run(t, b.Prefix("!policy disable "+tt.disable))
+
+			for _, customID := range tt.denied {
+				result := b.Click(nowPlaying, customID)
+				if kind := utils.KindOf(result.Err); kind != utils.KindForbidden {
+					t.Errorf("%s: got error kind %s, want forbidden: %v", customID, kind, result.Err)
+				}
+			}
+			if current := b.Player.Guild(*b.GuildID).Current; current == nil || current.Info.Title != "first song" {
+				t.Errorf("the denied controls changed the player, now playing %v", current)
+			}
+
+			for _, customID := range tt.allowed {
+				b.run(t, b.Click(nowPlaying, customID))
+			}
+		})
+	}
+}
+
+func TestPolicyCoversEqualizerModal(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	b.run(t, b.Prefix("!play song"))
+	b.run(t, b.Prefix("!policy disable equalizer"))
+
+	result := b.Submit("equalizer", map[string]string{"bass": "0.5", "mids": "0", "treble": "0"})
+	if kind := utils.KindOf(result.Err); kind != utils.KindForbidden {
+		t.Fatalf("got error kind %s, want forbidden: %v", kind, result.Err)
+	}
+	if equalizer := b.Player.Guild(*b.GuildID).Equalizer; equalizer[0] != 0 {
+		t.Errorf("the equalizer was changed to %v", equalizer)
+	}
+}
+
+func TestPolicyInThreads(t *testing.T) {
+	const general, other = 200, 202
+
+	tests := []struct {
+		name   string
+		rule   string
+		parent snowflake.ID
+		denied bool
+	}{
+		{name: "allowed in the parent", rule: "allow", parent: general},
+		{name: "allowed elsewhere", rule: "allow", parent: other, denied: true},
+		{name: "denied in the parent", rule: "deny", parent: general, denied: true},
+		{name: "denied elsewhere", rule: "deny", parent: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, registry.Options{})
+			b.run(t, b.Prefix(fmt.Sprintf("!policy %s help <#%d>", tt.rule, general)))
+
+			b.ChannelID = 201
+			b.ThreadParentID = tt.parent
+			for _, result := range []*registrytest.Result{b.Prefix("!help"), b.Slash("help", nil)} {
+				if denied := utils.KindOf(result.Err) == utils.KindForbidden; denied != tt.denied {
+					t.Errorf("got error %v, want denied %v", result.Err, tt.denied)
+				}
+			}
+		})
+	}
+}
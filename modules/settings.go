@@ -0,0 +1,311 @@
+package modules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/i18n"
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/settings"
+	"github.com/goland-express/flexo/types"
+	"github.com/goland-express/flexo/utils"
+)
+
+const (
+	maxPrefixes     = 5
+	maxPrefixLength = 10
+)
+
+// SettingsModule lets guild admins change how the bot behaves in their guild.
+type SettingsModule struct {
+	DefaultPrefix string
+	Catalog       *i18n.Catalog
+
+	registry *registry.Registry
+}
+
+func (m *SettingsModule) Name() string {
+	return "Settings"
+}
+
+func (m *SettingsModule) Register(r *registry.Registry) {
+	m.registry = r
+	manageGuild := []registry.CheckFunc{registry.HasPermission(discord.PermissionManageGuild)}
+
+	r.Add(&registry.Command{
+		Name:          "prefix",
+		Description:   "Show or change the command prefixes of this server.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		Aliases:       []string{"prefixes"},
+		Checks:        []registry.CheckFunc{registry.GuildOnly(), settingsAvailable},
+		Contexts:      guildContexts,
+		Execute:       m.executePrefixShow,
+		SubCommands: []*registry.Command{
+			{
+				Name:        "show",
+				Description: "Show the command prefixes of this server.",
+				Aliases:     []string{"list"},
+				Execute:     m.executePrefixShow,
+			},
+			{
+				Name:        "set",
+				Description: "Replace the command prefixes of this server.",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{Name: "prefixes", Description: "Prefixes separated by spaces", Required: true},
+				},
+				Checks:   manageGuild,
+				Examples: []string{"prefix set ?", "prefix set ? fx!"},
+				Execute:  m.executePrefixSet,
+			},
+			{
+				Name:        "add",
+				Description: "Add a command prefix to this server.",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{Name: "prefix", Description: "Prefix to add", Required: true, MaxLength: utils.Ptr(maxPrefixLength)},
+				},
+				Checks:   manageGuild,
+				Examples: []string{"prefix add ?"},
+				Execute:  m.executePrefixAdd,
+			},
+			{
+				Name:        "remove",
+				Description: "Remove a command prefix from this server.",
+				Aliases:     []string{"rm"},
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{Name: "prefix", Description: "Prefix to remove", Required: true},
+				},
+				Checks:   manageGuild,
+				Examples: []string{"prefix remove ?"},
+				Execute:  m.executePrefixRemove,
+			},
+			{
+				Name:        "reset",
+				Description: "Go back to the default command prefix.",
+				Checks:      manageGuild,
+				Confirm:     "Replace the prefixes of this server with the default one?",
+				Execute:     m.executePrefixReset,
+			},
+		},
+	})
+
+	r.Add(&registry.Command{
+		Name:          "suggestions",
+		Description:   "Show or change whether mistyped commands get suggestions.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionBool{Name: "enabled", Description: "Whether to suggest commands"},
+		},
+		Checks:   []registry.CheckFunc{registry.GuildOnly(), settingsAvailable},
+		Contexts: guildContexts,
+		Examples: []string{"suggestions", "suggestions off"},
+		Execute:  m.executeSuggestions,
+	})
+
+	languages := make([]discord.ApplicationCommandOptionChoiceString, 0, len(m.Catalog.Locales()))
+	for _, locale := range m.Catalog.Locales() {
+		languages = append(languages, discord.ApplicationCommandOptionChoiceString{Name: discord.Locales[locale], Value: locale.Code()})
+	}
+
+	r.Add(&registry.Command{
+		Name:          "language",
+		Description:   "Show or change the language prefix commands answer in.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		Aliases:       []string{"lang"},
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{Name: "language", Description: "Language to use", Choices: languages},
+		},
+		Checks:   []registry.CheckFunc{registry.GuildOnly(), settingsAvailable},
+		Contexts: guildContexts,
+		Examples: []string{"language", "language pt-BR"},
+		Execute:  m.executeLanguage,
+	})
+
+	m.registerPolicy(r)
+}
+
+// Locale returns the language configured for the guild, empty when it uses
+// the default or when the configured language no longer has a catalog.
+func (m *SettingsModule) Locale(guild settings.Guild) discord.Locale {
+	locale := discord.Locale(guild.Language)
+	if !m.Catalog.Supports(locale) {
+		return ""
+	}
+	return locale
+}
+
+// Prefixes returns the prefixes configured for the guild, or the default one.
+func (m *SettingsModule) Prefixes(guild settings.Guild) []string {
+	if len(guild.Prefixes) == 0 {
+		return []string{m.DefaultPrefix}
+	}
+	return guild.Prefixes
+}
+
+func (m *SettingsModule) executePrefixShow(ctx *registry.Context) error {
+	prefixes := m.Prefixes(getSettings(ctx).Guild(*ctx.GuildID()))
+
+	quoted := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		quoted[i] = "`" + prefix + "`"
+	}
+
+	message := ctx.T("settings.prefix.show", strings.Join(quoted, ", "), ctx.Client().ID())
+	if err := ctx.Say(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func (m *SettingsModule) executePrefixSet(ctx *registry.Context) error {
+	input, _ := ctx.GetStringOption("prefixes")
+
+	var prefixes []string
+	for _, prefix := range strings.Fields(input) {
+		if !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	return m.savePrefixes(ctx, prefixes)
+}
+
+func (m *SettingsModule) executePrefixAdd(ctx *registry.Context) error {
+	prefix, _ := ctx.GetStringOption("prefix")
+
+	prefixes := m.Prefixes(getSettings(ctx).Guild(*ctx.GuildID()))
+	if !slices.Contains(prefixes, prefix) {
+		prefixes = append(prefixes, prefix)
+	}
+
+	return m.savePrefixes(ctx, prefixes)
+}
+
+func (m *SettingsModule) executePrefixRemove(ctx *registry.Context) error {
+	prefix, _ := ctx.GetStringOption("prefix")
+
+	prefixes := m.Prefixes(getSettings(ctx).Guild(*ctx.GuildID()))
+	if !slices.Contains(prefixes, prefix) {
+		return &utils.UserError{Kind: utils.KindNotFound, Message: ctx.T("settings.prefix.not_found", prefix)}
+	}
+
+	prefixes = slices.DeleteFunc(prefixes, func(p string) bool { return p == prefix })
+	if len(prefixes) == 0 {
+		return &utils.UserError{Message: ctx.T("settings.prefix.last", ctx.Prefix())}
+	}
+
+	return m.savePrefixes(ctx, prefixes)
+}
+
+func (m *SettingsModule) executePrefixReset(ctx *registry.Context) error {
+	return m.savePrefixes(ctx, nil)
+}
+
+func (m *SettingsModule) savePrefixes(ctx *registry.Context, prefixes []string) error {
+	if len(prefixes) > maxPrefixes {
+		return &utils.UserError{Message: ctx.T("settings.prefix.too_many", maxPrefixes)}
+	}
+	for _, prefix := range prefixes {
+		if len([]rune(prefix)) > maxPrefixLength || strings.ContainsFunc(prefix, unicode.IsSpace) {
+			return &utils.UserError{Message: ctx.T("settings.prefix.invalid", maxPrefixLength)}
+		}
+	}
+
+	// Storing the default keeps the guild following it if it changes.
+	if slices.Equal(prefixes, []string{m.DefaultPrefix}) {
+		prefixes = nil
+	}
+
+	err := getSettings(ctx).Update(*ctx.GuildID(), func(g *settings.Guild) {
+		g.Prefixes = prefixes
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save prefixes: %w", err)
+	}
+
+	return m.executePrefixShow(ctx)
+}
+
+func (m *SettingsModule) executeSuggestions(ctx *registry.Context) error {
+	store := getSettings(ctx)
+	guildID := *ctx.GuildID()
+
+	enabled, ok := ctx.GetBoolOption("enabled")
+	if ok {
+		if err := registry.HasPermission(discord.PermissionManageGuild)(ctx); err != nil {
+			return err
+		}
+
+		err := store.Update(guildID, func(g *settings.Guild) {
+			g.DisableSuggestions = !enabled
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save suggestions setting: %w", err)
+		}
+	} else {
+		enabled = !store.Guild(guildID).DisableSuggestions
+	}
+
+	message := ctx.T("settings.suggestions.enabled")
+	if !enabled {
+		message = ctx.T("settings.suggestions.disabled")
+	}
+	if err := ctx.Say(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func (m *SettingsModule) executeLanguage(ctx *registry.Context) error {
+	store := getSettings(ctx)
+	guildID := *ctx.GuildID()
+
+	if language, ok := ctx.GetStringOption("language"); ok {
+		if err := registry.HasPermission(discord.PermissionManageGuild)(ctx); err != nil {
+			return err
+		}
+
+		err := store.Update(guildID, func(g *settings.Guild) {
+			g.Language = language
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save language: %w", err)
+		}
+	}
+
+	locale := m.Locale(store.Guild(guildID))
+	if locale == "" {
+		locale = m.Catalog.Fallback()
+	}
+
+	// Slash commands answer in the invoker's own language, the setting only
+	// applies to prefix commands.
+	if err := ctx.Say(m.Catalog.T(locale, "settings.language.show", discord.Locales[locale])); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func settingsAvailable(ctx *registry.Context) error {
+	botData, ok := ctx.Data().(*types.BotData)
+	if !ok {
+		return ErrInvalidBotData
+	}
+
+	if botData.Settings == nil {
+		return &utils.UserError{Kind: utils.KindUnavailable, Message: ctx.T("settings.unavailable")}
+	}
+
+	return nil
+}
+
+// getSettings must only be called from commands guarded by settingsAvailable.
+func getSettings(ctx *registry.Context) *settings.Store {
+	return ctx.Data().(*types.BotData).Settings
+}
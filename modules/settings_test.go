@@ -0,0 +1,48 @@
+package modules_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/i18n"
+	"github.com/goland-express/flexo/modules"
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/settings"
+)
+
+func TestLanguage(t *testing.T) {
+	b := newTestBot(t, registry.Options{})
+	b.Permissions = discord.PermissionManageGuild
+
+	response := b.run(t, b.Prefix("!language pt-BR")).Last()
+	if !strings.HasPrefix(response.Content, "Comandos com prefixo respondem em") {
+		t.Errorf("got %q, want the language shown in Portuguese", response.Content)
+	}
+	if language := b.Settings.Guild(*b.GuildID).Language; language != "pt-BR" {
+		t.Errorf("got language %q saved, want pt-BR", language)
+	}
+}
+
+func TestLanguageWithoutCatalog(t *testing.T) {
+	m := &modules.SettingsModule{Catalog: i18n.MustEmbedded(discord.LocaleEnglishUS)}
+
+	tests := []struct {
+		language string
+		want     discord.Locale
+	}{
+		{language: "", want: ""},
+		{language: "pt-BR", want: discord.LocalePortugueseBR},
+		// Languages without a catalog, like one removed since it was set,
+		// answer in the default language and are shown as such.
+		{language: "de", want: ""},
+		{language: "Portuguese", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := m.Locale(settings.Guild{Language: tt.language}); got != tt.want {
+			t.Errorf("Locale(%q) = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
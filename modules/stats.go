@@ -0,0 +1,156 @@
+package modules
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/utils"
+)
+
+const (
+	statsTopCommands = 15
+	statsTopGuilds   = 10
+)
+
+// StatsModule shows the bot owners how commands are being used.
+type StatsModule struct {
+	registry *registry.Registry
+}
+
+func (m *StatsModule) Name() string {
+	return "Stats"
+}
+
+func (m *StatsModule) Register(r *registry.Registry) {
+	m.registry = r
+
+	r.Add(&registry.Command{
+		Name:          "stats",
+		Description:   "Show usage statistics of the bot.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		Hidden:        true,
+		Checks:        []registry.CheckFunc{registry.OwnerOnly()},
+		SubCommands: []*registry.Command{
+			{
+				Name:        "commands",
+				Description: "Show how often commands are used, how they fail and how fast they are.",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{Name: "command", Description: "Command to show details for"},
+				},
+				Examples: []string{"stats commands", `stats commands "queue remove"`},
+				Execute:  m.executeStatsCommands,
+			},
+		},
+	})
+}
+
+func (m *StatsModule) executeStatsCommands(ctx *registry.Context) error {
+	var embed discord.Embed
+	if name, ok := ctx.GetStringOption("command"); ok && name != "" {
+		command := strings.Join(strings.Fields(strings.ToLower(name)), " ")
+		metrics, ok := m.registry.Metrics().Command(command)
+		if !ok {
+			return &utils.UserError{Kind: utils.KindNotFound, Message: ctx.T("stats.not_found", command)}
+		}
+		embed = buildCommandStatsEmbed(ctx, command, metrics)
+	} else {
+		embed = buildCommandsStatsEmbed(ctx, m.registry.Metrics().Snapshot())
+	}
+
+	if err := ctx.Respond(registry.Response{Embeds: []discord.Embed{embed}, Ephemeral: true}); err != nil {
+		return fmt.Errorf("failed to send embed: %w", err)
+	}
+	return nil
+}
+
+func buildCommandsStatsEmbed(ctx *registry.Context, snapshot map[string]registry.CommandMetrics) discord.Embed {
+	embed := discord.NewEmbedBuilder().
+		SetTitle(ctx.T("stats.commands.title")).
+		SetColor(0x5865F2).
+		SetTimestamp(time.Now())
+
+	if len(snapshot) == 0 {
+		return embed.SetDescription(ctx.T("stats.commands.empty")).Build()
+	}
+
+	commands := make([]string, 0, len(snapshot))
+	var total registry.CommandMetrics
+	for command, metrics := range snapshot {
+		commands = append(commands, command)
+		total.Invocations += metrics.Invocations
+		total.Slash += metrics.Slash
+		total.Prefix += metrics.Prefix
+		total.Errors += metrics.Errors
+		total.UserErrors += metrics.UserErrors
+	}
+	slices.SortFunc(commands, func(a, b string) int {
+		return cmp.Or(cmp.Compare(snapshot[b].Invocations, snapshot[a].Invocations), cmp.Compare(a, b))
+	})
+
+	embed.SetDescription(ctx.T("stats.commands.total", total.Invocations, total.Slash, total.Prefix, total.UserErrors, total.Failures()))
+	for _, command := range commands[:min(len(commands), statsTopCommands)] {
+		metrics := snapshot[command]
+		embed.AddField(command, ctx.T("stats.commands.entry",
+			metrics.Invocations, metrics.Slash, metrics.Prefix, metrics.UserErrors, metrics.Failures(),
+			roundLatency(metrics.Percentile(50)), roundLatency(metrics.Percentile(95)), roundLatency(metrics.Percentile(99)),
+		), false)
+	}
+	if len(commands) > statsTopCommands {
+		embed.SetFooter(ctx.N("stats.commands.more", len(commands)-statsTopCommands), "")
+	}
+	return embed.Build()
+}
+
+func buildCommandStatsEmbed(ctx *registry.Context, command string, metrics registry.CommandMetrics) discord.Embed {
+	embed := discord.NewEmbedBuilder().
+		SetTitle(ctx.T("stats.command.title", command)).
+		SetColor(0x5865F2).
+		SetTimestamp(time.Now()).
+		AddField(ctx.T("stats.command.uses"), ctx.T("stats.command.uses_value", metrics.Invocations, metrics.Slash, metrics.Prefix), false).
+		AddField(ctx.T("stats.command.errors"), ctx.T("stats.command.errors_value", metrics.UserErrors, metrics.Failures()), false).
+		AddField(ctx.T("stats.command.latency"), ctx.T("stats.command.latency_value",
+			roundLatency(metrics.Average()), roundLatency(metrics.Percentile(50)), roundLatency(metrics.Percentile(95)),
+			roundLatency(metrics.Percentile(99)), roundLatency(metrics.Max),
+		), false)
+
+	guilds := make([]snowflake.ID, 0, len(metrics.Guilds))
+	for guildID := range metrics.Guilds {
+		guilds = append(guilds, guildID)
+	}
+	slices.SortFunc(guilds, func(a, b snowflake.ID) int {
+		return cmp.Or(cmp.Compare(metrics.Guilds[b], metrics.Guilds[a]), cmp.Compare(a, b))
+	})
+
+	if len(guilds) > 0 {
+		var sb strings.Builder
+		for _, guildID := range guilds[:min(len(guilds), statsTopGuilds)] {
+			name := guildID.String()
+			if guild, ok := ctx.Client().Caches().Guild(guildID); ok {
+				name = guild.Name
+			}
+			sb.WriteString(ctx.T("stats.command.guild", name, metrics.Guilds[guildID]) + "\n")
+		}
+		embed.AddField(ctx.T("stats.command.guilds"), sb.String(), false)
+	}
+	return embed.Build()
+}
+
+// roundLatency keeps latencies readable, the percentiles are estimates anyway.
+func roundLatency(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -0,0 +1,31 @@
+package player
+
+import (
+	"context"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Manager is the part of Player the commands use, so tests can replace the
+// Lavalink connection with playertest.Player.
+type Manager interface {
+	Play(ctx context.Context, client bot.Client, guildID, channelID snowflake.ID, query string, userData map[string]any) (*lavalink.Track, int, error)
+	Stop(ctx context.Context, guildID snowflake.ID) error
+	Pause(ctx context.Context, guildID snowflake.ID, paused bool) error
+	IsPaused(guildID snowflake.ID) bool
+	GetCurrentTrack(guildID snowflake.ID) *lavalink.Track
+	Position(guildID snowflake.ID) lavalink.Duration
+	SetEqualizer(ctx context.Context, guildID snowflake.ID, equalizer lavalink.Equalizer) error
+	GetEqualizer(guildID snowflake.ID) lavalink.Equalizer
+	Search(ctx context.Context, query string, source ...string) ([]lavalink.Track, error)
+
+	GetQueue(ctx context.Context, guildID snowflake.ID) (*Queue, error)
+	NextTrack(ctx context.Context, guildID snowflake.ID) (*lavalink.Track, error)
+	ShuffleQueue(ctx context.Context, guildID snowflake.ID) error
+	ClearQueue(ctx context.Context, guildID snowflake.ID) error
+	RemoveTrack(ctx context.Context, guildID snowflake.ID, trackID int) error
+}
+
+var _ Manager = (*Player)(nil)
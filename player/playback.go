@@ -131,12 +131,40 @@ func (p *Player) SetVolume(ctx context.Context, guildID snowflake.ID, volume int
 	return nil
 }
 
+func (p *Player) SetEqualizer(ctx context.Context, guildID snowflake.ID, equalizer lavalink.Equalizer) error {
+	player := p.client.Player(guildID)
+
+	filters := player.Filters()
+	filters.Equalizer = &equalizer
+	if err := player.Update(ctx, lavalink.WithFilters(filters)); err != nil {
+		return fmt.Errorf("failed to set equalizer: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Player) GetEqualizer(guildID snowflake.ID) lavalink.Equalizer {
+	player := p.client.Player(guildID)
+
+	if equalizer := player.Filters().Equalizer; equalizer != nil {
+		return *equalizer
+	}
+	return lavalink.Equalizer{}
+}
+
 func (p *Player) GetCurrentTrack(guildID snowflake.ID) *lavalink.Track {
 	player := p.client.Player(guildID)
 
 	return player.Track()
 }
 
+// Position returns how far into the current track the player is.
+func (p *Player) Position(guildID snowflake.ID) lavalink.Duration {
+	player := p.client.Player(guildID)
+
+	return player.Position()
+}
+
 func (p *Player) IsPlaying(guildID snowflake.ID) bool {
 	player := p.client.Player(guildID)
 
@@ -149,14 +177,18 @@ func (p *Player) IsPaused(guildID snowflake.ID) bool {
 	return player.Paused()
 }
 
-func (p *Player) loadTrack(ctx context.Context, query string, source ...string) (*lavalink.Track, error) {
-	identifier := query
-
+func identifierFor(query string, source ...string) string {
 	if len(source) > 0 && source[0] != "" {
-		identifier = lavalink.SearchType(source[0]).Apply(identifier)
-	} else if !urlPattern.MatchString(identifier) && !searchPattern.MatchString(identifier) {
-		identifier = lavalink.SearchTypeYouTubeMusic.Apply(identifier)
+		return lavalink.SearchType(source[0]).Apply(query)
 	}
+	if !urlPattern.MatchString(query) && !searchPattern.MatchString(query) {
+		return lavalink.SearchTypeYouTubeMusic.Apply(query)
+	}
+	return query
+}
+
+func (p *Player) loadTrack(ctx context.Context, query string, source ...string) (*lavalink.Track, error) {
+	identifier := identifierFor(query, source...)
 
 	var (
 		toPlay    *lavalink.Track
@@ -194,6 +226,12 @@ func (p *Player) loadTrack(ctx context.Context, query string, source ...string)
 	return toPlay, nil
 }
 
+// Search resolves query the same way Play does but returns every result
+// instead of picking the first one.
+func (p *Player) Search(ctx context.Context, query string, source ...string) ([]lavalink.Track, error) {
+	return p.LoadPlaylist(ctx, identifierFor(query, source...))
+}
+
 func (p *Player) LoadPlaylist(ctx context.Context, url string) ([]lavalink.Track, error) {
 	var (
 		tracks    []lavalink.Track
@@ -0,0 +1,284 @@
+// Package playertest provides an in-memory player.Manager for testing music
+// commands without a Lavalink node.
+package playertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/player"
+)
+
+var _ player.Manager = (*Player)(nil)
+
+// Guild is the state of the fake player in one guild.
+type Guild struct {
+	ChannelID snowflake.ID
+	Current   *lavalink.Track
+	Position  lavalink.Duration
+	Paused    bool
+	Equalizer lavalink.Equalizer
+	Queue     []lavalink.Track
+}
+
+// Player plays tracks instantly and keeps its queues in memory. Queries are
+// resolved through Tracks, unknown ones produce a track titled after the
+// query and an empty result makes the search fail. Setting Err makes every
+// call that can fail return it.
+type Player struct {
+	mu     sync.Mutex
+	guilds map[snowflake.ID]*Guild
+
+	Tracks map[string][]lavalink.Track
+	Err    error
+}
+
+func New() *Player {
+	return &Player{
+		guilds: make(map[snowflake.ID]*Guild),
+		Tracks: make(map[string][]lavalink.Track),
+	}
+}
+
+// Track builds a track with the given title and length in milliseconds.
+func Track(title string, length lavalink.Duration) lavalink.Track {
+	uri := "https://example.com/" + strings.ReplaceAll(strings.ToLower(title), " ", "-")
+	return lavalink.Track{
+		Encoded: title,
+		Info: lavalink.TrackInfo{
+			Identifier: title,
+			Title:      title,
+			Author:     "Test Artist",
+			Length:     length,
+			URI:        &uri,
+			SourceName: "test",
+		},
+	}
+}
+
+// Guild returns a copy of the player's state in the guild.
+func (p *Player) Guild(guildID snowflake.ID) Guild {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	guild := *p.guild(guildID)
+	guild.Queue = append([]lavalink.Track(nil), guild.Queue...)
+	return guild
+}
+
+// SetGuild replaces the player's state in the guild.
+func (p *Player) SetGuild(guildID snowflake.ID, guild Guild) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.guilds[guildID] = &guild
+}
+
+func (p *Player) guild(guildID snowflake.ID) *Guild {
+	guild, ok := p.guilds[guildID]
+	if !ok {
+		guild = &Guild{}
+		p.guilds[guildID] = guild
+	}
+	return guild
+}
+
+func (p *Player) search(query string) ([]lavalink.Track, error) {
+	if tracks, ok := p.Tracks[query]; ok {
+		if len(tracks) == 0 {
+			return nil, player.ErrNoResultsFound
+		}
+		return tracks, nil
+	}
+
+	track := Track(query, 180_000)
+	if strings.HasPrefix(query, "http://") || strings.HasPrefix(query, "https://") {
+		track.Info.URI = &query
+	}
+	return []lavalink.Track{track}, nil
+}
+
+func (p *Player) Play(_ context.Context, _ bot.Client, guildID, channelID snowflake.ID, query string, userData map[string]any) (*lavalink.Track, int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, 0, p.Err
+	}
+
+	tracks, err := p.search(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	track := tracks[0]
+	rawData, err := json.Marshal(userData)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal user data: %w", err)
+	}
+	track.UserData = rawData
+
+	guild := p.guild(guildID)
+	guild.ChannelID = channelID
+	if guild.Current == nil {
+		guild.Current = &track
+		guild.Position = 0
+		return &track, 1, nil
+	}
+
+	guild.Queue = append(guild.Queue, track)
+	return &track, len(guild.Queue), nil
+}
+
+func (p *Player) Stop(_ context.Context, guildID snowflake.ID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+
+	guild := p.guild(guildID)
+	guild.Current = nil
+	guild.Position = 0
+	return nil
+}
+
+func (p *Player) Pause(_ context.Context, guildID snowflake.ID, paused bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+
+	p.guild(guildID).Paused = paused
+	return nil
+}
+
+func (p *Player) IsPaused(guildID snowflake.ID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.guild(guildID).Paused
+}
+
+func (p *Player) GetCurrentTrack(guildID snowflake.ID) *lavalink.Track {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.guild(guildID).Current
+}
+
+func (p *Player) Position(guildID snowflake.ID) lavalink.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.guild(guildID).Position
+}
+
+func (p *Player) SetEqualizer(_ context.Context, guildID snowflake.ID, equalizer lavalink.Equalizer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+
+	p.guild(guildID).Equalizer = equalizer
+	return nil
+}
+
+func (p *Player) GetEqualizer(guildID snowflake.ID) lavalink.Equalizer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.guild(guildID).Equalizer
+}
+
+func (p *Player) Search(_ context.Context, query string, _ ...string) ([]lavalink.Track, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return p.search(query)
+}
+
+func (p *Player) GetQueue(_ context.Context, guildID snowflake.ID) (*player.Queue, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return &player.Queue{Tracks: append([]lavalink.Track(nil), p.guild(guildID).Queue...)}, nil
+}
+
+func (p *Player) NextTrack(_ context.Context, guildID snowflake.ID) (*lavalink.Track, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	guild := p.guild(guildID)
+	guild.Position = 0
+	if len(guild.Queue) == 0 {
+		guild.Current = nil
+		return nil, player.ErrQueueEmpty
+	}
+
+	track := guild.Queue[0]
+	guild.Queue = guild.Queue[1:]
+	guild.Current = &track
+	return &track, nil
+}
+
+func (p *Player) ShuffleQueue(_ context.Context, guildID snowflake.ID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+
+	queue := p.guild(guildID).Queue
+	rand.Shuffle(len(queue), func(i, j int) {
+		queue[i], queue[j] = queue[j], queue[i]
+	})
+	return nil
+}
+
+func (p *Player) ClearQueue(_ context.Context, guildID snowflake.ID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+
+	p.guild(guildID).Queue = nil
+	return nil
+}
+
+func (p *Player) RemoveTrack(_ context.Context, guildID snowflake.ID, trackID int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Err != nil {
+		return p.Err
+	}
+
+	guild := p.guild(guildID)
+	if trackID < 0 || trackID >= len(guild.Queue) {
+		return fmt.Errorf("no track at position %d", trackID)
+	}
+	guild.Queue = append(guild.Queue[:trackID:trackID], guild.Queue[trackID+1:]...)
+	return nil
+}
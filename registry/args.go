@@ -0,0 +1,403 @@
+package registry
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+var (
+	userMentionPattern    = regexp.MustCompile(`^<@!?(\d+)>$`)
+	roleMentionPattern    = regexp.MustCompile(`^<@&(\d+)>$`)
+	channelMentionPattern = regexp.MustCompile(`^<#(\d+)>$`)
+)
+
+type argToken struct {
+	value  string
+	index  int
+	start  int
+	end    int
+	quoted bool
+}
+
+// tokenize splits input on whitespace while keeping quoted sections together.
+// Offsets into the original input are kept so a trailing option can take the
+// remaining text verbatim.
+func tokenize(input string) []argToken {
+	var (
+		tokens []argToken
+		sb     strings.Builder
+		quote  rune
+		start  = -1
+		quoted bool
+	)
+
+	flush := func(end int) {
+		if start == -1 {
+			return
+		}
+		tokens = append(tokens, argToken{value: sb.String(), index: len(tokens), start: start, end: end, quoted: quoted})
+		sb.Reset()
+		start = -1
+		quoted = false
+	}
+
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0 && r == '\\' && i+size < len(input):
+			next, nextSize := utf8.DecodeRuneInString(input[i+size:])
+			if next == quote || next == '\\' {
+				sb.WriteRune(next)
+				size += nextSize
+			} else {
+				sb.WriteRune(r)
+			}
+		case quote != 0:
+			sb.WriteRune(r)
+		case unicode.IsSpace(r):
+			flush(i)
+		case (r == '"' || r == '\'') && start == -1:
+			start = i
+			quote = r
+			quoted = true
+		default:
+			if start == -1 {
+				start = i
+			}
+			sb.WriteRune(r)
+		}
+
+		i += size
+	}
+	flush(len(input))
+
+	return tokens
+}
+
+func splitFirstArg(input string) (string, string) {
+	input = strings.TrimSpace(input)
+	idx := strings.IndexFunc(input, unicode.IsSpace)
+	if idx == -1 {
+		return input, ""
+	}
+	return input[:idx], strings.TrimSpace(input[idx:])
+}
+
+// parseArgs resolves a prefix invocation against the options declared on a
+// command. Flags (--name value) may appear anywhere, the remaining tokens are
+// assigned positionally and a trailing string option receives the rest of the
+// input untouched.
+func parseArgs(ctx *Context, input string, options []discord.ApplicationCommandOption) (map[string]any, error) {
+	tokens := tokenize(input)
+	values := make(map[string]any, len(options))
+
+	positional := make([]argToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.quoted || !strings.HasPrefix(token.value, "--") || len(token.value) <= 2 {
+			positional = append(positional, token)
+			continue
+		}
+
+		name, inline, hasInline := strings.Cut(token.value[2:], "=")
+		if strings.EqualFold(name, "yes") {
+			name = forceOption
+		}
+		option := findOption(options, name)
+		if option == nil {
+			positional = append(positional, token)
+			continue
+		}
+
+		var raw string
+		switch {
+		case hasInline:
+			raw = inline
+		case option.Type() == discord.ApplicationCommandOptionTypeBool:
+			raw = "true"
+			if i+1 < len(tokens) {
+				if _, ok := parseBool(tokens[i+1].value); ok {
+					raw = tokens[i+1].value
+					i++
+				}
+			}
+		case i+1 < len(tokens):
+			raw = tokens[i+1].value
+			i++
+		default:
+			return nil, &utils.UserError{Message: ctx.T("args.flag_needs_value", option.OptionName())}
+		}
+
+		value, err := convertArg(ctx, option, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[option.OptionName()] = value
+	}
+
+	attachments := ctx.attachments()
+	for i, option := range options {
+		name := option.OptionName()
+		if _, ok := values[name]; ok {
+			continue
+		}
+
+		if option.Type() == discord.ApplicationCommandOptionTypeAttachment {
+			if len(attachments) == 0 {
+				if isRequired(option) {
+					return nil, &utils.UserError{Message: ctx.T("args.missing_attachment", name)}
+				}
+				continue
+			}
+			values[name] = attachments[0]
+			attachments = attachments[1:]
+			continue
+		}
+
+		if len(positional) == 0 {
+			if isRequired(option) {
+				return nil, &utils.UserError{Message: ctx.T("args.missing_argument", name)}
+			}
+			continue
+		}
+
+		raw := positional[0].value
+		consumed := 1
+		if option.Type() == discord.ApplicationCommandOptionTypeString && isLastUnset(options[i+1:], values) {
+			raw = joinTokens(input, positional)
+			consumed = len(positional)
+		}
+
+		value, err := convertArg(ctx, option, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		values[name] = value
+		positional = positional[consumed:]
+	}
+
+	if len(positional) > 0 {
+		return nil, &utils.UserError{Message: ctx.T("args.unexpected_argument", positional[0].value)}
+	}
+
+	return values, nil
+}
+
+func joinTokens(input string, tokens []argToken) string {
+	if len(tokens) == 1 {
+		return tokens[0].value
+	}
+
+	first, last := tokens[0], tokens[len(tokens)-1]
+	if last.index-first.index == len(tokens)-1 {
+		return input[first.start:last.end]
+	}
+
+	parts := make([]string, len(tokens))
+	for i, token := range tokens {
+		parts[i] = input[token.start:token.end]
+	}
+	return strings.Join(parts, " ")
+}
+
+func findOption(options []discord.ApplicationCommandOption, name string) discord.ApplicationCommandOption {
+	for _, option := range options {
+		if strings.EqualFold(option.OptionName(), name) {
+			return option
+		}
+	}
+	return nil
+}
+
+func isLastUnset(options []discord.ApplicationCommandOption, values map[string]any) bool {
+	for _, option := range options {
+		if _, ok := values[option.OptionName()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isRequired(option discord.ApplicationCommandOption) bool {
+	switch o := option.(type) {
+	case discord.ApplicationCommandOptionString:
+		return o.Required
+	case discord.ApplicationCommandOptionInt:
+		return o.Required
+	case discord.ApplicationCommandOptionFloat:
+		return o.Required
+	case discord.ApplicationCommandOptionBool:
+		return o.Required
+	case discord.ApplicationCommandOptionUser:
+		return o.Required
+	case discord.ApplicationCommandOptionChannel:
+		return o.Required
+	case discord.ApplicationCommandOptionRole:
+		return o.Required
+	case discord.ApplicationCommandOptionMentionable:
+		return o.Required
+	case discord.ApplicationCommandOptionAttachment:
+		return o.Required
+	}
+	return false
+}
+
+func convertArg(ctx *Context, option discord.ApplicationCommandOption, raw string) (any, error) {
+	name := option.OptionName()
+
+	switch o := option.(type) {
+	case discord.ApplicationCommandOptionString:
+		if len(o.Choices) > 0 {
+			for _, choice := range o.Choices {
+				if strings.EqualFold(choice.Name, raw) || strings.EqualFold(choice.Value, raw) {
+					return choice.Value, nil
+				}
+			}
+			return nil, invalidChoice(ctx, name, raw)
+		}
+
+		length := utf8.RuneCountInString(raw)
+		if o.MinLength != nil && length < *o.MinLength {
+			return nil, &utils.UserError{Message: ctx.T("args.min_length", name, *o.MinLength)}
+		}
+		if o.MaxLength != nil && length > *o.MaxLength {
+			return nil, &utils.UserError{Message: ctx.T("args.max_length", name, *o.MaxLength)}
+		}
+		return raw, nil
+
+	case discord.ApplicationCommandOptionInt:
+		for _, choice := range o.Choices {
+			if strings.EqualFold(choice.Name, raw) {
+				return int64(choice.Value), nil
+			}
+		}
+
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, &utils.UserError{Message: ctx.T("args.not_integer", name)}
+		}
+
+		if len(o.Choices) > 0 {
+			for _, choice := range o.Choices {
+				if int64(choice.Value) == value {
+					return value, nil
+				}
+			}
+			return nil, invalidChoice(ctx, name, raw)
+		}
+
+		if o.MinValue != nil && value < int64(*o.MinValue) {
+			return nil, &utils.UserError{Message: ctx.T("args.min_int", name, *o.MinValue)}
+		}
+		if o.MaxValue != nil && value > int64(*o.MaxValue) {
+			return nil, &utils.UserError{Message: ctx.T("args.max_int", name, *o.MaxValue)}
+		}
+		return value, nil
+
+	case discord.ApplicationCommandOptionFloat:
+		for _, choice := range o.Choices {
+			if strings.EqualFold(choice.Name, raw) {
+				return choice.Value, nil
+			}
+		}
+
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, &utils.UserError{Message: ctx.T("args.not_number", name)}
+		}
+
+		if len(o.Choices) > 0 {
+			for _, choice := range o.Choices {
+				if choice.Value == value {
+					return value, nil
+				}
+			}
+			return nil, invalidChoice(ctx, name, raw)
+		}
+
+		if o.MinValue != nil && value < *o.MinValue {
+			return nil, &utils.UserError{Message: ctx.T("args.min_float", name, *o.MinValue)}
+		}
+		if o.MaxValue != nil && value > *o.MaxValue {
+			return nil, &utils.UserError{Message: ctx.T("args.max_float", name, *o.MaxValue)}
+		}
+		return value, nil
+
+	case discord.ApplicationCommandOptionBool:
+		value, ok := parseBool(raw)
+		if !ok {
+			return nil, &utils.UserError{Message: ctx.T("args.not_bool", name)}
+		}
+		return value, nil
+
+	case discord.ApplicationCommandOptionUser:
+		id, ok := parseMention(userMentionPattern, raw)
+		if !ok {
+			return nil, &utils.UserError{Message: ctx.T("args.not_user", name)}
+		}
+		return ctx.resolveUser(id)
+
+	case discord.ApplicationCommandOptionChannel:
+		id, ok := parseMention(channelMentionPattern, raw)
+		if !ok {
+			return nil, &utils.UserError{Message: ctx.T("args.not_channel", name)}
+		}
+		return id, nil
+
+	case discord.ApplicationCommandOptionRole:
+		id, ok := parseMention(roleMentionPattern, raw)
+		if !ok {
+			return nil, &utils.UserError{Message: ctx.T("args.not_role", name)}
+		}
+		return id, nil
+
+	case discord.ApplicationCommandOptionMentionable:
+		if id, ok := parseMention(roleMentionPattern, raw); ok {
+			return id, nil
+		}
+		if id, ok := parseMention(userMentionPattern, raw); ok {
+			return id, nil
+		}
+		return nil, &utils.UserError{Message: ctx.T("args.not_mentionable", name)}
+	}
+
+	return nil, &utils.UserError{Kind: utils.KindUnavailable, Message: ctx.T("args.slash_only", name)}
+}
+
+func invalidChoice(ctx *Context, name, raw string) error {
+	return &utils.UserError{Message: ctx.T("args.invalid_choice", raw, name)}
+}
+
+func parseBool(raw string) (bool, bool) {
+	switch strings.ToLower(raw) {
+	case "true", "yes", "y", "on", "1", "enable", "enabled":
+		return true, true
+	case "false", "no", "n", "off", "0", "disable", "disabled":
+		return false, true
+	}
+	return false, false
+}
+
+func parseMention(pattern *regexp.Regexp, raw string) (snowflake.ID, bool) {
+	if match := pattern.FindStringSubmatch(raw); match != nil {
+		raw = match[1]
+	}
+
+	id, err := snowflake.Parse(raw)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return id, true
+}
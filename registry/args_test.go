@@ -0,0 +1,236 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "empty", input: "   ", want: nil},
+		{name: "whitespace", input: " never  gonna\tgive ", want: []string{"never", "gonna", "give"}},
+		{name: "double quotes", input: `add "never gonna" give`, want: []string{"add", "never gonna", "give"}},
+		{name: "single quotes", input: `'you up' now`, want: []string{"you up", "now"}},
+		{name: "empty quotes", input: `"" x`, want: []string{"", "x"}},
+		{name: "escaped quote", input: `"say \"hi\"" \"x`, want: []string{`say "hi"`, `\"x`}},
+		{name: "other quote inside", input: `"it's" fine`, want: []string{"it's", "fine"}},
+		{name: "unterminated", input: `"never gonna`, want: []string{"never gonna"}},
+		{name: "quote inside word", input: `don't stop`, want: []string{"don't", "stop"}},
+		{name: "unicode", input: "«olá» 'mundo é'", want: []string{"«olá»", "mundo é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, token := range tokenize(tt.input) {
+				got = append(got, token.value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	input := `play "rick astley"  now`
+	for _, token := range tokenize(input) {
+		raw := input[token.start:token.end]
+		if token.quoted {
+			raw = raw[1 : len(raw)-1]
+		}
+		if raw != token.value {
+			t.Errorf("token %d spans %q, want %q", token.index, input[token.start:token.end], token.value)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	query := discord.ApplicationCommandOptionString{Name: "query", Required: true}
+	position := discord.ApplicationCommandOptionInt{
+		Name:     "position",
+		Required: true,
+		MinValue: utils.Ptr(1),
+		MaxValue: utils.Ptr(10),
+	}
+	volume := discord.ApplicationCommandOptionFloat{Name: "volume", MinValue: utils.Ptr(0.5), MaxValue: utils.Ptr(2.0)}
+	shuffle := discord.ApplicationCommandOptionBool{Name: "shuffle"}
+	force := discord.ApplicationCommandOptionBool{Name: forceOption}
+
+	tests := []struct {
+		name    string
+		input   string
+		options []discord.ApplicationCommandOption
+		want    map[string]any
+		err     string
+	}{
+		{
+			name:    "trailing string keeps the input",
+			input:   `never  gonna "give"`,
+			options: []discord.ApplicationCommandOption{query},
+			want:    map[string]any{"query": `never  gonna "give"`},
+		},
+		{
+			name:    "quoted positional",
+			input:   `"never gonna" 3`,
+			options: []discord.ApplicationCommandOption{discord.ApplicationCommandOptionString{Name: "title"}, position},
+			want:    map[string]any{"title": "never gonna", "position": int64(3)},
+		},
+		{
+			name:    "flag first",
+			input:   "--position 4 never gonna",
+			options: []discord.ApplicationCommandOption{query, position},
+			want:    map[string]any{"query": "never gonna", "position": int64(4)},
+		},
+		{
+			name:    "flag in the middle",
+			input:   "never --shuffle gonna",
+			options: []discord.ApplicationCommandOption{query, shuffle},
+			want:    map[string]any{"query": "never gonna", "shuffle": true},
+		},
+		{
+			name:    "flag last with a value",
+			input:   "never gonna --shuffle off",
+			options: []discord.ApplicationCommandOption{query, shuffle},
+			want:    map[string]any{"query": "never gonna", "shuffle": false},
+		},
+		{
+			name:    "inline flag",
+			input:   "--volume=1.5 never",
+			options: []discord.ApplicationCommandOption{query, volume},
+			want:    map[string]any{"query": "never", "volume": 1.5},
+		},
+		{
+			name:    "yes is force",
+			input:   "--yes",
+			options: []discord.ApplicationCommandOption{force},
+			want:    map[string]any{forceOption: true},
+		},
+		{
+			name:    "quoted flag is text",
+			input:   `"--shuffle"`,
+			options: []discord.ApplicationCommandOption{query, shuffle},
+			want:    map[string]any{"query": "--shuffle"},
+		},
+		{
+			name:    "unknown flag is text",
+			input:   "--loud song",
+			options: []discord.ApplicationCommandOption{query},
+			want:    map[string]any{"query": "--loud song"},
+		},
+		{
+			name:    "optional left out",
+			input:   "",
+			options: []discord.ApplicationCommandOption{shuffle, volume},
+			want:    map[string]any{},
+		},
+		{
+			name:    "optional given",
+			input:   "1.5 yes",
+			options: []discord.ApplicationCommandOption{volume, shuffle},
+			want:    map[string]any{"volume": 1.5, "shuffle": true},
+		},
+		{
+			name:    "optional out of range",
+			input:   "3",
+			options: []discord.ApplicationCommandOption{volume},
+			err:     "`volume` must be at most 2.",
+		},
+		{
+			name:    "optional not a number",
+			input:   "loud",
+			options: []discord.ApplicationCommandOption{volume},
+			err:     "`volume` must be a number.",
+		},
+		{
+			name:    "invalid optional isn't skipped",
+			input:   "maybe 1.5",
+			options: []discord.ApplicationCommandOption{shuffle, volume},
+			err:     "`shuffle` must be yes or no.",
+		},
+		{
+			name:    "missing required",
+			input:   "",
+			options: []discord.ApplicationCommandOption{position},
+			err:     "Missing required argument `position`.",
+		},
+		{
+			name:    "missing required after a flag",
+			input:   "--shuffle",
+			options: []discord.ApplicationCommandOption{query, shuffle},
+			err:     "Missing required argument `query`.",
+		},
+		{
+			name:    "flag without a value",
+			input:   "song --position",
+			options: []discord.ApplicationCommandOption{query, position},
+			err:     "Flag `--position` needs a value.",
+		},
+		{
+			name:    "below the minimum",
+			input:   "0",
+			options: []discord.ApplicationCommandOption{position},
+			err:     "`position` must be at least 1.",
+		},
+		{
+			name:    "above the maximum",
+			input:   "11",
+			options: []discord.ApplicationCommandOption{position},
+			err:     "`position` must be at most 10.",
+		},
+		{
+			name:    "float out of range as a flag",
+			input:   "song --volume 3",
+			options: []discord.ApplicationCommandOption{query, volume},
+			err:     "`volume` must be at most 2.",
+		},
+		{
+			name:    "not a number",
+			input:   "third",
+			options: []discord.ApplicationCommandOption{position},
+			err:     "`position` must be a whole number.",
+		},
+		{
+			name:    "too many arguments",
+			input:   "3 4",
+			options: []discord.ApplicationCommandOption{position},
+			err:     "Unexpected argument `4`.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseArgs(newArgsContext(), tt.input, tt.options)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newArgsContext returns a prefix context outside of guilds, answering in the
+// catalog's fallback locale.
+func newArgsContext() *Context {
+	return &Context{
+		registry:    New(Options{}),
+		messageData: &events.MessageCreate{GenericMessage: &events.GenericMessage{}},
+	}
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Discord rejects autocomplete responses with more choices than this.
+const maxAutocompleteChoices = 25
+
+type AutocompleteFunc func(ctx *AutocompleteContext) ([]discord.AutocompleteChoice, error)
+
+type AutocompleteContext struct {
+	client bot.Client
+	event  *events.AutocompleteInteractionCreate
+	data   Data
+}
+
+func (c *AutocompleteContext) Client() bot.Client {
+	return c.client
+}
+
+func (c *AutocompleteContext) Data() Data {
+	return c.data
+}
+
+func (c *AutocompleteContext) GuildID() *snowflake.ID {
+	return c.event.GuildID()
+}
+
+func (c *AutocompleteContext) Author() discord.User {
+	return c.event.User()
+}
+
+// Focused returns the name of the option being typed in.
+func (c *AutocompleteContext) Focused() string {
+	return c.event.Data.Focused().Name
+}
+
+// Value returns what the user typed so far in the focused option. Numeric
+// options are returned in their textual form.
+func (c *AutocompleteContext) Value() string {
+	raw := c.event.Data.Focused().Value
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+	return string(raw)
+}
+
+// Options gives access to the other options already filled in.
+func (c *AutocompleteContext) Options() discord.AutocompleteInteractionData {
+	return c.event.Data
+}
+
+func (r *Registry) OnAutocomplete(event *events.AutocompleteInteractionCreate) {
+	cmd := r.find(event.Data.CommandName, true)
+	if cmd == nil {
+		return
+	}
+
+	for _, name := range []*string{event.Data.SubCommandGroupName, event.Data.SubCommandName} {
+		if name == nil {
+			continue
+		}
+		if cmd = cmd.SubCommand(*name); cmd == nil {
+			return
+		}
+	}
+
+	focused := event.Data.Focused().Name
+	handler, ok := cmd.Autocomplete[focused]
+	if !ok {
+		return
+	}
+
+	choices, err := handler(&AutocompleteContext{
+		client: event.Client(),
+		event:  event,
+		data:   r.data,
+	})
+	if err != nil {
+		slog.Warn("Autocomplete handler failed",
+			slog.String("command", event.Data.CommandPath()),
+			slog.String("option", focused),
+			slog.Any("error", err),
+		)
+		choices = nil
+	}
+
+	if len(choices) > maxAutocompleteChoices {
+		choices = choices[:maxAutocompleteChoices]
+	}
+	if choices == nil {
+		choices = []discord.AutocompleteChoice{}
+	}
+
+	if err := event.AutocompleteResult(choices); err != nil {
+		slog.Error("Failed to send autocomplete result", slog.Any("error", err))
+	}
+}
+
+// withAutocomplete flags the options that have a handler so Discord sends
+// autocomplete interactions for them.
+func withAutocomplete(options []discord.ApplicationCommandOption, handlers map[string]AutocompleteFunc) []discord.ApplicationCommandOption {
+	if len(handlers) == 0 {
+		return options
+	}
+
+	flagged := make([]discord.ApplicationCommandOption, len(options))
+	for i, option := range options {
+		if _, ok := handlers[option.OptionName()]; ok {
+			switch o := option.(type) {
+			case discord.ApplicationCommandOptionString:
+				o.Autocomplete = true
+				option = o
+			case discord.ApplicationCommandOptionInt:
+				o.Autocomplete = true
+				option = o
+			case discord.ApplicationCommandOptionFloat:
+				o.Autocomplete = true
+				option = o
+			}
+		}
+		flagged[i] = option
+	}
+	return flagged
+}
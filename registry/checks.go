@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+// CheckFunc runs before a command executes. Returning an error prevents the
+// command from running and hands the error to the registry's OnError.
+type CheckFunc func(ctx *Context) error
+
+// runChecks runs the registry-wide checks, then the ones of every command in
+// the path from the root down.
+func (r *Registry) runChecks(ctx *Context, path []*Command) error {
+	for _, check := range r.checks {
+		if err := check(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, cmd := range path {
+		for _, check := range cmd.Checks {
+			if err := check(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CheckCommand runs the registry-wide checks and the ones of the command at
+// path, like "queue clear", as if it had been invoked. Component handlers use
+// it for buttons that do what another command does.
+func (c *Context) CheckCommand(path string) error {
+	previous := c.path
+	defer func() { c.path = previous }()
+	return c.registry.checkOwner(c, path)
+}
+
+// checkOwner makes the command at path the one the context invokes and runs
+// its checks, an empty path runs none.
+func (r *Registry) checkOwner(ctx *Context, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	commands := r.Lookup(path)
+	if commands == nil {
+		return fmt.Errorf("unknown command %q", path)
+	}
+	ctx.path = commands
+	return r.runChecks(ctx, commands)
+}
+
+func GuildOnly() CheckFunc {
+	return func(ctx *Context) error {
+		if ctx.GuildID() == nil {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.guild_only")}
+		}
+		return nil
+	}
+}
+
+func InVoiceChannel() CheckFunc {
+	return func(ctx *Context) error {
+		if err := GuildOnly()(ctx); err != nil {
+			return err
+		}
+
+		if _, ok := ctx.VoiceChannelID(); !ok {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.in_voice_channel")}
+		}
+		return nil
+	}
+}
+
+// SameVoiceChannel requires the author to share the bot's voice channel. When
+// the bot isn't connected anywhere in the guild, being in any voice channel is
+// enough.
+func SameVoiceChannel() CheckFunc {
+	return func(ctx *Context) error {
+		if err := InVoiceChannel()(ctx); err != nil {
+			return err
+		}
+
+		botState, ok := ctx.Client().Caches().VoiceState(*ctx.GuildID(), ctx.Client().ApplicationID())
+		if !ok || botState.ChannelID == nil {
+			return nil
+		}
+
+		if channelID, _ := ctx.VoiceChannelID(); channelID != *botState.ChannelID {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.same_voice_channel", *botState.ChannelID)}
+		}
+		return nil
+	}
+}
+
+// HasRole passes when the author has at least one of the given roles.
+func HasRole(roleIDs ...snowflake.ID) CheckFunc {
+	return func(ctx *Context) error {
+		if err := GuildOnly()(ctx); err != nil {
+			return err
+		}
+
+		if member := ctx.Member(); member != nil {
+			for _, roleID := range member.RoleIDs {
+				if slices.Contains(roleIDs, roleID) {
+					return nil
+				}
+			}
+		}
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.has_role")}
+	}
+}
+
+func HasPermission(permissions discord.Permissions) CheckFunc {
+	return func(ctx *Context) error {
+		if err := GuildOnly()(ctx); err != nil {
+			return err
+		}
+
+		if !ctx.Permissions().Has(permissions) {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.has_permission", PermissionNames(permissions))}
+		}
+		return nil
+	}
+}
+
+func OwnerOnly() CheckFunc {
+	return func(ctx *Context) error {
+		if !ctx.IsOwner() {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.owner_only")}
+		}
+		return nil
+	}
+}
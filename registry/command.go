@@ -1,6 +1,14 @@
 package registry
 
-import "github.com/disgoorg/disgo/discord"
+import (
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/json"
+
+	"github.com/goland-express/flexo/i18n"
+)
 
 type ExecuteFunc func(ctx *Context) error
 
@@ -12,4 +20,176 @@ type Command struct {
 	Aliases       []string
 	Execute       ExecuteFunc
 	Options       []discord.ApplicationCommandOption
+	Autocomplete  map[string]AutocompleteFunc
+	Checks        []CheckFunc
+	Cooldown      *Cooldown
+	Middlewares   []Middleware
+	Hidden        bool
+	Examples      []string
+	Module        string
+
+	// SubCommands turns the command into a slash subcommand (or group, when the
+	// children have subcommands of their own). Execute on a parent is only
+	// reachable from prefix invocations that don't name a subcommand. Parents
+	// can't have Options or Confirm and groups can't hold groups, like in
+	// Discord, Add panics otherwise.
+	SubCommands []*Command
+
+	// UserCommand and MessageCommand add the command to the context menu of
+	// users or messages under Name. The target is available through
+	// Context.TargetUser and Context.TargetMessage.
+	UserCommand    bool
+	MessageCommand bool
+
+	// Confirm makes the invoker confirm with this prompt before the command
+	// runs, it is localized as <CommandKey>.confirm. A force option is added
+	// to skip it, prefix invocations can also pass --yes.
+	Confirm string
+
+	// DefaultMemberPermissions, Contexts, IntegrationTypes and NSFW are
+	// registered with Discord and only apply to top-level commands. Prefix
+	// invocations are held to the same restrictions, except for overrides
+	// made in the server's integration settings.
+
+	// DefaultMemberPermissions are the permissions members need to use the
+	// command, zero leaves it to everyone.
+	DefaultMemberPermissions discord.Permissions
+
+	// Contexts are where the command can be used, everywhere when empty. Leave
+	// out InteractionContextTypeBotDM to keep it out of DMs.
+	Contexts []discord.InteractionContextType
+
+	// IntegrationTypes are the installations the command is available to,
+	// Discord defaults to guild installs.
+	IntegrationTypes []discord.ApplicationIntegrationType
+
+	// NSFW limits the command to age-restricted channels.
+	NSFW bool
+}
+
+// Matches reports whether name is the command's name or one of its aliases,
+// ignoring case.
+func (c *Command) Matches(name string) bool {
+	return strings.EqualFold(c.Name, name) || slices.ContainsFunc(c.Aliases, func(alias string) bool {
+		return strings.EqualFold(alias, name)
+	})
+}
+
+func (c *Command) SubCommand(name string) *Command {
+	for _, sub := range c.SubCommands {
+		if sub.Matches(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Usage describes the command's options, <required> and [optional], in the
+// order the prefix parser expects them.
+func (c *Command) Usage() string {
+	parts := make([]string, len(c.Options))
+	for i, option := range c.Options {
+		if isRequired(option) {
+			parts[i] = "<" + option.OptionName() + ">"
+		} else {
+			parts[i] = "[" + option.OptionName() + "]"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Command) subCommandNames() string {
+	names := make([]string, len(c.SubCommands))
+	for i, sub := range c.SubCommands {
+		names[i] = "`" + sub.Name + "`"
+	}
+	return strings.Join(names, ", ")
+}
+
+func (c *Command) slashCommandCreate(catalog *i18n.Catalog) discord.SlashCommandCreate {
+	key := CommandKey([]*Command{c})
+	create := discord.SlashCommandCreate{
+		Name:                     c.Name,
+		NameLocalizations:        catalog.Localizations(key + ".name"),
+		Description:              c.Description,
+		DescriptionLocalizations: catalog.Localizations(key + ".description"),
+		Options:                  localizeOptions(withAutocomplete(c.AllOptions(), c.Autocomplete), key, catalog),
+		DefaultMemberPermissions: c.defaultMemberPermissions(),
+		IntegrationTypes:         c.IntegrationTypes,
+		Contexts:                 c.Contexts,
+		NSFW:                     &c.NSFW,
+	}
+
+	if len(c.SubCommands) > 0 {
+		create.Options = make([]discord.ApplicationCommandOption, 0, len(c.SubCommands))
+		for _, sub := range c.SubCommands {
+			if len(sub.SubCommands) == 0 {
+				create.Options = append(create.Options, sub.subCommandOption(catalog, []*Command{c, sub}))
+				continue
+			}
+
+			groupKey := CommandKey([]*Command{c, sub})
+			group := discord.ApplicationCommandOptionSubCommandGroup{
+				Name:                     sub.Name,
+				NameLocalizations:        catalog.Localizations(groupKey + ".name"),
+				Description:              sub.Description,
+				DescriptionLocalizations: catalog.Localizations(groupKey + ".description"),
+				Options:                  make([]discord.ApplicationCommandOptionSubCommand, 0, len(sub.SubCommands)),
+			}
+			for _, leaf := range sub.SubCommands {
+				group.Options = append(group.Options, leaf.subCommandOption(catalog, []*Command{c, sub, leaf}))
+			}
+			create.Options = append(create.Options, group)
+		}
+	}
+
+	return create
+}
+
+func (c *Command) subCommandOption(catalog *i18n.Catalog, path []*Command) discord.ApplicationCommandOptionSubCommand {
+	key := CommandKey(path)
+	return discord.ApplicationCommandOptionSubCommand{
+		Name:                     c.Name,
+		NameLocalizations:        catalog.Localizations(key + ".name"),
+		Description:              c.Description,
+		DescriptionLocalizations: catalog.Localizations(key + ".description"),
+		Options:                  localizeOptions(withAutocomplete(c.AllOptions(), c.Autocomplete), key, catalog),
+	}
+}
+
+// contextMenuCommandCreates builds the context menu entries of the command.
+func (c *Command) contextMenuCommandCreates(catalog *i18n.Catalog) []discord.ApplicationCommandCreate {
+	names := catalog.Localizations(CommandKey([]*Command{c}) + ".name")
+
+	var creates []discord.ApplicationCommandCreate
+	if c.UserCommand {
+		creates = append(creates, discord.UserCommandCreate{
+			Name:                     c.Name,
+			NameLocalizations:        names,
+			DefaultMemberPermissions: c.defaultMemberPermissions(),
+			IntegrationTypes:         c.IntegrationTypes,
+			Contexts:                 c.Contexts,
+			NSFW:                     &c.NSFW,
+		})
+	}
+	if c.MessageCommand {
+		creates = append(creates, discord.MessageCommandCreate{
+			Name:                     c.Name,
+			NameLocalizations:        names,
+			DefaultMemberPermissions: c.defaultMemberPermissions(),
+			IntegrationTypes:         c.IntegrationTypes,
+			Contexts:                 c.Contexts,
+			NSFW:                     &c.NSFW,
+		})
+	}
+	return creates
+}
+
+// defaultMemberPermissions is always sent, null when the command is left to
+// everyone, so that dropping the requirement is synced too.
+func (c *Command) defaultMemberPermissions() *json.Nullable[discord.Permissions] {
+	if c.DefaultMemberPermissions == 0 {
+		return json.NullPtr[discord.Permissions]()
+	}
+	return json.NewNullablePtr(c.DefaultMemberPermissions)
 }
@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+const (
+	customIDSeparator       = ":"
+	defaultComponentTimeout = 15 * time.Minute
+)
+
+type ComponentFunc func(ctx *ComponentContext) error
+
+// ComponentHandler handles button and select menu interactions whose custom ID
+// starts with Prefix. Components on messages that haven't been touched for
+// longer than Timeout are disabled instead of being handled.
+type ComponentHandler struct {
+	Prefix  string
+	Timeout time.Duration
+	Checks  []CheckFunc
+	Execute ComponentFunc
+
+	// Command is the path of the command the components belong to, like
+	// "play" or "queue clear". The registry-wide checks and the ones of the
+	// command run against it before Checks, so a command restricted in the
+	// guild can't be used through its buttons either.
+	Command string
+}
+
+// CustomID builds a custom ID the component router can dispatch, the params are
+// available to the handler through ComponentContext.Params.
+func CustomID(prefix string, params ...string) string {
+	return strings.Join(append([]string{prefix}, params...), customIDSeparator)
+}
+
+type ComponentContext struct {
+	*Context
+	event  *events.ComponentInteractionCreate
+	params []string
+}
+
+func (c *ComponentContext) Event() *events.ComponentInteractionCreate {
+	return c.event
+}
+
+func (c *ComponentContext) CustomID() string {
+	return c.event.Data.CustomID()
+}
+
+func (c *ComponentContext) Params() []string {
+	return c.params
+}
+
+// Param returns the i-th custom ID parameter, or an empty string when there
+// aren't that many.
+func (c *ComponentContext) Param(i int) string {
+	if i < 0 || i >= len(c.params) {
+		return ""
+	}
+	return c.params[i]
+}
+
+// Message returns the message the component is attached to.
+func (c *ComponentContext) Message() discord.Message {
+	return c.event.Message
+}
+
+// Values returns the selected values of a string select menu.
+func (c *ComponentContext) Values() []string {
+	if data, ok := c.event.Data.(discord.StringSelectMenuInteractionData); ok {
+		return data.Values
+	}
+	return nil
+}
+
+func (c *ComponentContext) UpdateMessage(update discord.MessageUpdate) error {
+	if err := c.initialResponse(discord.InteractionResponseTypeUpdateMessage, update); err != nil {
+		return fmt.Errorf("failed to update component message: %w", err)
+	}
+	return nil
+}
+
+// DeferUpdate acknowledges the interaction without changing the message, which
+// can then be edited later through the REST API.
+func (c *ComponentContext) DeferUpdate() error {
+	if err := c.initialResponse(discord.InteractionResponseTypeDeferredUpdateMessage, nil); err != nil {
+		return fmt.Errorf("failed to defer component update: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) AddComponent(handler *ComponentHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components[handler.Prefix] = handler
+}
+
+func (r *Registry) OnComponent(event *events.ComponentInteractionCreate) {
+	parts := strings.Split(event.Data.CustomID(), customIDSeparator)
+
+	r.mu.RLock()
+	handler, ok := r.components[parts[0]]
+	r.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	ctx := &ComponentContext{
+		Context: &Context{
+			registry:    r,
+			client:      event.Client(),
+			interaction: event.ComponentInteraction,
+			respond:     event.Respond,
+			data:        r.data,
+		},
+		event:  event,
+		params: parts[1:],
+	}
+
+	timeout := handler.Timeout
+	if timeout == 0 {
+		timeout = r.componentTimeout
+	}
+
+	lastActivity := event.Message.CreatedAt
+	if event.Message.EditedTimestamp != nil {
+		lastActivity = *event.Message.EditedTimestamp
+	}
+
+	if time.Since(lastActivity) > timeout {
+		err := ctx.UpdateMessage(discord.MessageUpdate{
+			Components: utils.Ptr(DisableComponents(event.Message.Components)),
+		})
+		if err != nil {
+			r.onError(err, ctx.Context)
+		}
+		return
+	}
+
+	if err := r.checkOwner(ctx.Context, handler.Command); err != nil {
+		r.onError(err, ctx.Context)
+		return
+	}
+
+	for _, check := range handler.Checks {
+		if err := check(ctx.Context); err != nil {
+			r.onError(err, ctx.Context)
+			return
+		}
+	}
+
+	execute := Recover()(func(*Context) error {
+		return handler.Execute(ctx)
+	})
+	if err := execute(ctx.Context); err != nil {
+		r.onError(err, ctx.Context)
+	}
+}
+
+// DisableComponents returns a copy of the given action rows with every button
+// and select menu disabled.
+func DisableComponents(components []discord.ContainerComponent) []discord.ContainerComponent {
+	disabled := make([]discord.ContainerComponent, 0, len(components))
+	for _, container := range components {
+		row, ok := container.(discord.ActionRowComponent)
+		if !ok {
+			disabled = append(disabled, container)
+			continue
+		}
+
+		newRow := make(discord.ActionRowComponent, len(row))
+		for i, component := range row {
+			switch c := component.(type) {
+			case discord.ButtonComponent:
+				if c.Style != discord.ButtonStyleLink {
+					component = c.AsDisabled()
+				}
+			case discord.StringSelectMenuComponent:
+				component = c.AsDisabled()
+			case discord.UserSelectMenuComponent:
+				component = c.AsDisabled()
+			case discord.RoleSelectMenuComponent:
+				component = c.AsDisabled()
+			case discord.MentionableSelectMenuComponent:
+				component = c.AsDisabled()
+			case discord.ChannelSelectMenuComponent:
+				component = c.AsDisabled()
+			}
+			newRow[i] = component
+		}
+		disabled = append(disabled, newRow)
+	}
+	return disabled
+}
@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+const (
+	confirmPrefix         = "confirm"
+	defaultConfirmTimeout = 30 * time.Second
+
+	// forceOption skips the confirmation of commands that ask for one, prefix
+	// invocations can also pass --yes.
+	forceOption = "force"
+)
+
+type confirmation struct {
+	authorID snowflake.ID
+	decision chan bool
+}
+
+// Confirm asks the invoker to confirm with Yes and No buttons and waits for
+// the answer. Anything but Yes within the timeout, see
+// Options.ConfirmTimeout, counts as No. The click arrives as another event,
+// so events have to be dispatched asynchronously.
+func (c *Context) Confirm(prompt string) (bool, error) {
+	r := c.registry
+	id := c.invocationID().String()
+	pending := &confirmation{authorID: c.Author().ID, decision: make(chan bool, 1)}
+
+	r.mu.Lock()
+	r.confirmations[id] = pending
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.confirmations[id] == pending {
+			delete(r.confirmations, id)
+		}
+	}()
+
+	edit, err := c.send(c.messageCreate(Response{
+		Content: prompt,
+		Components: []discord.ContainerComponent{
+			discord.NewActionRow(
+				discord.NewDangerButton(c.T("confirm.yes"), CustomID(confirmPrefix, id, "yes")),
+				discord.NewSecondaryButton(c.T("confirm.no"), CustomID(confirmPrefix, id, "no")),
+			),
+		},
+		Ephemeral: true,
+		Reply:     true,
+	}))
+	if err != nil {
+		return false, err
+	}
+
+	timer := time.NewTimer(r.confirmTimeout)
+	defer timer.Stop()
+
+	// Waiting on the invoker isn't part of the command's latency, see
+	// Metrics.Middleware.
+	waiting := time.Now()
+	var confirmed, answered bool
+	select {
+	case confirmed = <-pending.decision:
+		answered = true
+	case <-timer.C:
+	}
+	c.confirmWait += time.Since(waiting)
+	if answered {
+		return confirmed, nil
+	}
+
+	err = edit(discord.MessageUpdate{
+		Content:    utils.Ptr(c.T("confirm.expired")),
+		Components: &[]discord.ContainerComponent{},
+	})
+	if err != nil {
+		slog.Warn("Failed to expire confirmation", slog.String("confirmation", id), slog.Any("error", err))
+	}
+	return false, nil
+}
+
+// confirmCommand asks for the confirmation the invoked command declares,
+// unless the force option was passed.
+func (r *Registry) confirmCommand(ctx *Context) (bool, error) {
+	cmd := ctx.Command()
+	if cmd.Confirm == "" {
+		return true, nil
+	}
+	if force, _ := ctx.GetBoolOption(forceOption); force {
+		return true, nil
+	}
+	return ctx.Confirm(ctx.Localize(CommandKey(ctx.path)+".confirm", cmd.Confirm))
+}
+
+// AllOptions returns the options invocations are parsed against: Options,
+// followed by the force option when the command asks for confirmation.
+func (c *Command) AllOptions() []discord.ApplicationCommandOption {
+	if c.Confirm == "" || findOption(c.Options, forceOption) != nil {
+		return c.Options
+	}
+	return append(slices.Clone(c.Options), discord.ApplicationCommandOptionBool{Name: forceOption, Description: "Skip the confirmation"})
+}
+
+func (r *Registry) handleConfirm(ctx *ComponentContext) error {
+	r.mu.RLock()
+	pending := r.confirmations[ctx.Param(0)]
+	r.mu.RUnlock()
+
+	if pending == nil {
+		return ctx.UpdateMessage(discord.MessageUpdate{
+			Content:    utils.Ptr(ctx.T("confirm.expired")),
+			Components: &[]discord.ContainerComponent{},
+		})
+	}
+	if ctx.Author().ID != pending.authorID {
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("confirm.author_only", discord.UserMention(pending.authorID))}
+	}
+
+	confirmed := ctx.Param(1) == "yes"
+	select {
+	case pending.decision <- confirmed:
+	default:
+		// Answered already.
+	}
+
+	update := discord.MessageUpdate{Components: &[]discord.ContainerComponent{}}
+	if !confirmed {
+		update.Content = utils.Ptr(ctx.T("confirm.cancelled"))
+	}
+	return ctx.UpdateMessage(update)
+}
@@ -1,21 +1,41 @@
 package registry
 
 import (
-	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
 )
 
 type Context struct {
+	registry    *Registry
 	client      bot.Client
 	messageData *events.MessageCreate
 	slashData   *events.ApplicationCommandInteractionCreate
+	interaction discord.Interaction
+	respond     events.InteractionResponderFunc
 	data        Data
 	isSlash     bool
+	prefix      string
+	rawArgs     string
+	options     map[string]any
+	path        []*Command
+
+	responseMu sync.Mutex
+	responded  bool
+	deferred   bool
+	response   *discord.Message
+	editing    bool
+
+	// confirmWait is the time spent waiting for answers to Confirm.
+	confirmWait time.Duration
 }
 
 func (c *Context) Client() bot.Client {
@@ -30,16 +50,55 @@ func (c *Context) IsSlash() bool {
 	return c.isSlash
 }
 
-func (c *Context) ChannelID() snowflake.ID {
+// Prefix returns the prefix the command was invoked with, or "/" for slash
+// commands.
+func (c *Context) Prefix() string {
 	if c.isSlash {
-		return c.slashData.Channel().ID()
+		return "/"
+	}
+	return c.prefix
+}
+
+func (c *Context) Command() *Command {
+	if len(c.path) == 0 {
+		return nil
+	}
+	return c.path[len(c.path)-1]
+}
+
+// Path returns the invoked command preceded by its parents, root first.
+func (c *Context) Path() []*Command {
+	return c.path
+}
+
+func (c *Context) CommandPath() string {
+	return commandPath(c.path)
+}
+
+func commandPath(path []*Command) string {
+	names := make([]string, len(path))
+	for i, cmd := range path {
+		names[i] = cmd.Name
+	}
+	return strings.Join(names, " ")
+}
+
+// IsInteraction reports whether the context was created from an interaction
+// (slash command, component or modal) rather than a prefix message.
+func (c *Context) IsInteraction() bool {
+	return c.interaction != nil
+}
+
+func (c *Context) ChannelID() snowflake.ID {
+	if c.interaction != nil {
+		return c.interaction.Channel().ID()
 	}
 	return c.messageData.ChannelID
 }
 
 func (c *Context) GuildID() *snowflake.ID {
-	if c.isSlash {
-		return c.slashData.GuildID()
+	if c.interaction != nil {
+		return c.interaction.GuildID()
 	}
 	if c.messageData.Message.GuildID != nil {
 		return c.messageData.Message.GuildID
@@ -48,125 +107,233 @@ func (c *Context) GuildID() *snowflake.ID {
 }
 
 func (c *Context) Author() discord.User {
-	if c.isSlash {
-		return c.slashData.User()
+	if c.interaction != nil {
+		return c.interaction.User()
 	}
 	return c.messageData.Message.Author
 }
 
-func (c *Context) Say(content string) error {
-	builder := discord.NewMessageCreateBuilder().SetContent(content)
-
-	if c.isSlash {
-		if err := c.slashData.CreateMessage(builder.Build()); err != nil {
-			return fmt.Errorf("failed to create slash command response: %w", err)
+func (c *Context) Member() *discord.Member {
+	if c.interaction != nil {
+		if member := c.interaction.Member(); member != nil {
+			return &member.Member
 		}
 		return nil
 	}
 
-	_, err := c.messageData.Client().Rest().CreateMessage(
-		c.messageData.ChannelID,
-		builder.Build(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create prefix command message: %w", err)
+	member := c.messageData.Message.Member
+	if member == nil {
+		return nil
 	}
-	return nil
+	member.User = c.messageData.Message.Author
+	return member
 }
 
-func (c *Context) Reply(content string) error {
-	builder := discord.NewMessageCreateBuilder().SetContent(content)
-
-	if c.isSlash {
-		if err := c.slashData.CreateMessage(builder.Build()); err != nil {
-			return fmt.Errorf("failed to create slash reply response: %w", err)
+// Permissions returns the author's permissions in the current channel. Prefix
+// invocations resolve them from the role and channel caches, in threads from
+// the parent channel.
+func (c *Context) Permissions() discord.Permissions {
+	if c.interaction != nil {
+		if member := c.interaction.Member(); member != nil {
+			return member.Permissions
 		}
-		return nil
+		return discord.PermissionsNone
 	}
 
-	builder.SetMessageReference(&discord.MessageReference{
-		MessageID: &c.messageData.Message.ID,
-	})
+	member := c.Member()
+	if member == nil {
+		return discord.PermissionsNone
+	}
 
-	_, err := c.messageData.Client().Rest().CreateMessage(
-		c.messageData.ChannelID,
-		builder.Build(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create prefix reply message: %w", err)
+	if channel, ok := c.guildChannel(c.ChannelID()); ok {
+		return c.client.Caches().MemberPermissionsInChannel(channel, *member)
 	}
-	return nil
+	return c.client.Caches().MemberPermissions(*member)
 }
 
-func (c *Context) SendEmbed(embed discord.Embed) error {
-	builder := discord.NewMessageCreateBuilder().SetEmbeds(embed)
-
-	if c.isSlash {
-		if err := c.slashData.CreateMessage(builder.Build()); err != nil {
-			return fmt.Errorf("failed to create slash embed response: %w", err)
-		}
-		return nil
+func (c *Context) VoiceChannelID() (snowflake.ID, bool) {
+	guildID := c.GuildID()
+	if guildID == nil {
+		return 0, false
 	}
 
-	_, err := c.messageData.Client().Rest().CreateMessage(
-		c.messageData.ChannelID,
-		builder.Build(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create prefix embed message: %w", err)
+	voiceState, ok := c.client.Caches().VoiceState(*guildID, c.Author().ID)
+	if !ok || voiceState.ChannelID == nil {
+		return 0, false
 	}
-	return nil
+	return *voiceState.ChannelID, true
+}
+
+func (c *Context) IsOwner() bool {
+	return c.registry != nil && slices.Contains(c.registry.owners, c.Author().ID)
+}
+
+func (c *Context) Say(content string) error {
+	return c.Respond(Response{Content: content})
+}
+
+func (c *Context) Reply(content string) error {
+	return c.Respond(Response{Content: content, Reply: true})
+}
+
+func (c *Context) SendEmbed(embed discord.Embed, components ...discord.ContainerComponent) error {
+	return c.Respond(Response{Embeds: []discord.Embed{embed}, Components: components})
 }
 
 func (c *Context) Args() []string {
 	if c.isSlash {
 		return []string{}
 	}
-	content := c.messageData.Message.Content
 
-	parts := strings.Fields(content)
+	tokens := tokenize(c.rawArgs)
+	args := make([]string, len(tokens))
+	for i, token := range tokens {
+		args[i] = token.value
+	}
+	return args
+}
 
-	if len(parts) <= 1 {
-		return []string{}
+func (c *Context) RawArgs() string {
+	return c.rawArgs
+}
+
+// slashOptions returns the options of a slash command, context menu commands
+// have none.
+func (c *Context) slashOptions() discord.SlashCommandInteractionData {
+	data, _ := c.slashData.Data.(discord.SlashCommandInteractionData)
+	return data
+}
+
+// TargetUser returns the user a user context menu command was used on.
+func (c *Context) TargetUser() (discord.User, bool) {
+	if c.slashData == nil {
+		return discord.User{}, false
+	}
+	data, ok := c.slashData.Data.(discord.UserCommandInteractionData)
+	if !ok {
+		return discord.User{}, false
 	}
-	return parts[1:]
+	return data.TargetUser(), true
 }
 
-func (c *Context) GetStringOption(name string) (string, bool) {
-	if !c.isSlash {
-		return "", false
+// TargetMember returns the member a user context menu command was used on,
+// when it was used in a guild.
+func (c *Context) TargetMember() (*discord.ResolvedMember, bool) {
+	if c.slashData == nil {
+		return nil, false
+	}
+	data, ok := c.slashData.Data.(discord.UserCommandInteractionData)
+	if !ok || data.Resolved.Members == nil {
+		return nil, false
+	}
+	member, ok := data.Resolved.Members[data.TargetID()]
+	if !ok {
+		return nil, false
+	}
+	member.User = data.TargetUser()
+	return &member, true
+}
+
+// TargetMessage returns the message a message context menu command was used on.
+func (c *Context) TargetMessage() (discord.Message, bool) {
+	if c.slashData == nil {
+		return discord.Message{}, false
 	}
+	data, ok := c.slashData.Data.(discord.MessageCommandInteractionData)
+	if !ok {
+		return discord.Message{}, false
+	}
+	return data.TargetMessage(), true
+}
 
-	data := c.slashData.SlashCommandInteractionData()
-	return data.OptString(name)
+func (c *Context) GetStringOption(name string) (string, bool) {
+	if c.isSlash {
+		return c.slashOptions().OptString(name)
+	}
+	return prefixOption[string](c, name)
 }
 
 func (c *Context) GetIntOption(name string) (int64, bool) {
-	if !c.isSlash {
+	if c.isSlash {
+		if opt, ok := c.slashOptions().OptInt(name); ok {
+			return int64(opt), true
+		}
 		return 0, false
 	}
+	return prefixOption[int64](c, name)
+}
 
-	data := c.slashData.SlashCommandInteractionData()
-	if opt, ok := data.OptInt(name); ok {
-		return int64(opt), true
+func (c *Context) GetFloatOption(name string) (float64, bool) {
+	if c.isSlash {
+		return c.slashOptions().OptFloat(name)
 	}
-	return 0, false
+	return prefixOption[float64](c, name)
 }
 
 func (c *Context) GetBoolOption(name string) (bool, bool) {
-	if !c.isSlash {
-		return false, false
+	if c.isSlash {
+		return c.slashOptions().OptBool(name)
 	}
-
-	data := c.slashData.SlashCommandInteractionData()
-	return data.OptBool(name)
+	return prefixOption[bool](c, name)
 }
 
 func (c *Context) GetUserOption(name string) (discord.User, bool) {
-	if !c.isSlash {
-		return discord.User{}, false
+	if c.isSlash {
+		return c.slashOptions().OptUser(name)
 	}
+	return prefixOption[discord.User](c, name)
+}
 
-	data := c.slashData.SlashCommandInteractionData()
-	return data.OptUser(name)
+func (c *Context) GetChannelOption(name string) (snowflake.ID, bool) {
+	if c.isSlash {
+		return c.slashOptions().OptSnowflake(name)
+	}
+	return prefixOption[snowflake.ID](c, name)
+}
+
+func (c *Context) GetRoleOption(name string) (snowflake.ID, bool) {
+	if c.isSlash {
+		return c.slashOptions().OptSnowflake(name)
+	}
+	return prefixOption[snowflake.ID](c, name)
+}
+
+func (c *Context) GetMentionableOption(name string) (snowflake.ID, bool) {
+	if c.isSlash {
+		return c.slashOptions().OptSnowflake(name)
+	}
+	return prefixOption[snowflake.ID](c, name)
+}
+
+func (c *Context) GetAttachmentOption(name string) (discord.Attachment, bool) {
+	if c.isSlash {
+		return c.slashOptions().OptAttachment(name)
+	}
+	return prefixOption[discord.Attachment](c, name)
+}
+
+func prefixOption[T any](c *Context, name string) (T, bool) {
+	value, ok := c.options[name].(T)
+	return value, ok
+}
+
+func (c *Context) attachments() []discord.Attachment {
+	if c.messageData == nil {
+		return nil
+	}
+	return c.messageData.Message.Attachments
+}
+
+func (c *Context) resolveUser(id snowflake.ID) (discord.User, error) {
+	for _, user := range c.messageData.Message.Mentions {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+
+	user, err := c.client.Rest().GetUser(id)
+	if err != nil {
+		return discord.User{}, &utils.UserError{Kind: utils.KindNotFound, Message: c.T("args.user_not_found", id)}
+	}
+	return *user, nil
 }
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+type BucketType int
+
+const (
+	BucketUser BucketType = iota
+	BucketChannel
+	BucketGuild
+)
+
+// Cooldown limits how often a command can be used within a bucket. Each use
+// costs one token, a token is regained every Per and up to Burst tokens can be
+// saved up (defaults to 1).
+type Cooldown struct {
+	Per         time.Duration
+	Burst       int
+	Bucket      BucketType
+	BypassRoles []snowflake.ID
+}
+
+const cooldownSweepInterval = time.Minute
+
+type cooldowns struct {
+	mu        sync.Mutex
+	buckets   map[string]time.Time
+	lastSweep time.Time
+	// now is the clock buckets are measured against, replaced in tests.
+	now func() time.Time
+}
+
+func newCooldowns() *cooldowns {
+	return &cooldowns{
+		buckets:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// take consumes a token from the bucket identified by key and reports how long
+// the caller has to wait when none is left. It uses the generic cell rate
+// algorithm, so only the time the bucket is fully refilled has to be stored.
+func (c *cooldowns) take(key string, cooldown *Cooldown, now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) > cooldownSweepInterval {
+		for k, refilled := range c.buckets {
+			if refilled.Before(now) {
+				delete(c.buckets, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	burst := max(cooldown.Burst, 1)
+	tolerance := time.Duration(burst-1) * cooldown.Per
+
+	refilled := c.buckets[key]
+	if refilled.Before(now) {
+		refilled = now
+	}
+
+	if allowedAt := refilled.Add(-tolerance); allowedAt.After(now) {
+		return allowedAt.Sub(now)
+	}
+
+	c.buckets[key] = refilled.Add(cooldown.Per)
+	return 0
+}
+
+func (r *Registry) checkCooldown(ctx *Context) error {
+	var (
+		cooldown *Cooldown
+		path     []*Command
+	)
+	for i := len(ctx.path) - 1; i >= 0; i-- {
+		if ctx.path[i].Cooldown != nil {
+			cooldown = ctx.path[i].Cooldown
+			path = ctx.path[:i+1]
+			break
+		}
+	}
+
+	if cooldown == nil || cooldown.Per <= 0 {
+		return nil
+	}
+
+	if member := ctx.Member(); member != nil {
+		for _, roleID := range member.RoleIDs {
+			if slices.Contains(cooldown.BypassRoles, roleID) || slices.Contains(r.cooldownBypassRoles, roleID) {
+				return nil
+			}
+		}
+	}
+
+	key := commandPath(path) + ":" + bucketID(ctx, cooldown.Bucket).String()
+	wait := r.cooldowns.take(key, cooldown, r.cooldowns.now())
+	if wait <= 0 {
+		return nil
+	}
+
+	wait = time.Duration(math.Ceil(wait.Seconds())) * time.Second
+	return &utils.UserError{Kind: utils.KindCooldown, Message: ctx.T("cooldown.wait", wait)}
+}
+
+func bucketID(ctx *Context, bucket BucketType) snowflake.ID {
+	switch bucket {
+	case BucketChannel:
+		return ctx.ChannelID()
+	case BucketGuild:
+		if guildID := ctx.GuildID(); guildID != nil {
+			return *guildID
+		}
+		return ctx.ChannelID()
+	default:
+		return ctx.Author().ID
+	}
+}
@@ -0,0 +1,247 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+const (
+	cooldownGuild   snowflake.ID = 100
+	cooldownChannel snowflake.ID = 200
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestCooldownTake(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Each step uses the bucket at start+at and expects wait.
+	type step struct {
+		at   time.Duration
+		wait time.Duration
+	}
+	tests := []struct {
+		name     string
+		cooldown Cooldown
+		steps    []step
+	}{
+		{
+			name:     "single use",
+			cooldown: Cooldown{Per: 10 * time.Second},
+			steps: []step{
+				{at: 0},
+				{at: 3 * time.Second, wait: 7 * time.Second},
+				{at: 10 * time.Second},
+			},
+		},
+		{
+			name:     "burst exhausted",
+			cooldown: Cooldown{Per: 10 * time.Second, Burst: 3},
+			steps: []step{
+				{at: 0},
+				{at: 0},
+				{at: time.Second},
+				{at: 2 * time.Second, wait: 8 * time.Second},
+				{at: 10 * time.Second},
+				{at: 11 * time.Second, wait: 9 * time.Second},
+			},
+		},
+		{
+			name:     "burst refills over time",
+			cooldown: Cooldown{Per: 10 * time.Second, Burst: 2},
+			steps: []step{
+				{at: 0},
+				{at: 0},
+				{at: time.Minute},
+				{at: time.Minute},
+				{at: time.Minute, wait: 10 * time.Second},
+			},
+		},
+		{
+			name:     "failed uses cost nothing",
+			cooldown: Cooldown{Per: 10 * time.Second},
+			steps: []step{
+				{at: 0},
+				{at: time.Second, wait: 9 * time.Second},
+				{at: 2 * time.Second, wait: 8 * time.Second},
+				{at: 10 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCooldowns()
+			for i, step := range tt.steps {
+				if wait := c.take("key", &tt.cooldown, start.Add(step.at)); wait != step.wait {
+					t.Fatalf("step %d at %s: got wait %s, want %s", i, step.at, wait, step.wait)
+				}
+			}
+		})
+	}
+}
+
+func TestCooldownSweep(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCooldowns()
+	c.lastSweep = start
+
+	c.take("old", &Cooldown{Per: time.Second}, start)
+	c.take("new", &Cooldown{Per: time.Hour}, start.Add(2*cooldownSweepInterval))
+
+	if _, ok := c.buckets["old"]; ok {
+		t.Error("the refilled bucket wasn't swept")
+	}
+	if _, ok := c.buckets["new"]; !ok {
+		t.Error("the bucket in use was swept")
+	}
+}
+
+func TestCheckCooldown(t *testing.T) {
+	const bypassRole snowflake.ID = 300
+
+	play := &Command{Name: "play", Cooldown: &Cooldown{Per: 10 * time.Second, Burst: 2}}
+	queue := &Command{Name: "queue", Cooldown: &Cooldown{Per: 10 * time.Second, Bucket: BucketGuild}}
+	show := &Command{Name: "show"}
+	remove := &Command{Name: "remove"}
+	queue.SubCommands = []*Command{show, remove}
+	lyrics := &Command{Name: "lyrics", Cooldown: &Cooldown{Per: 10 * time.Second, Bucket: BucketChannel, BypassRoles: []snowflake.ID{bypassRole}}}
+
+	// Each use runs path as author, optionally in another channel or with the
+	// bypass role, and expects the error message, empty when it passes.
+	type use struct {
+		path    []*Command
+		author  snowflake.ID
+		channel snowflake.ID
+		bypass  bool
+		advance time.Duration
+		err     string
+	}
+	tests := []struct {
+		name   string
+		global []snowflake.ID
+		uses   []use
+	}{
+		{
+			name: "retry after",
+			uses: []use{
+				{path: []*Command{play}, author: 1},
+				{path: []*Command{play}, author: 1},
+				{path: []*Command{play}, author: 1, advance: 2500 * time.Millisecond, err: "Slow down! You can use this command again in 8s."},
+				{path: []*Command{play}, author: 1, advance: 7500 * time.Millisecond},
+			},
+		},
+		{
+			name: "users have their own bucket",
+			uses: []use{
+				{path: []*Command{play}, author: 1},
+				{path: []*Command{play}, author: 1},
+				{path: []*Command{play}, author: 2},
+				{path: []*Command{play}, author: 1, err: "Slow down! You can use this command again in 10s."},
+			},
+		},
+		{
+			name: "guild bucket shared by members and subcommands",
+			uses: []use{
+				{path: []*Command{queue, show}, author: 1},
+				{path: []*Command{queue, remove}, author: 2, err: "Slow down! You can use this command again in 10s."},
+				{path: []*Command{queue, show}, author: 3, channel: 201, advance: 4 * time.Second, err: "Slow down! You can use this command again in 6s."},
+			},
+		},
+		{
+			name: "channel bucket",
+			uses: []use{
+				{path: []*Command{lyrics}, author: 1},
+				{path: []*Command{lyrics}, author: 2, err: "Slow down! You can use this command again in 10s."},
+				{path: []*Command{lyrics}, author: 2, channel: 201},
+			},
+		},
+		{
+			name: "command bypass role",
+			uses: []use{
+				{path: []*Command{lyrics}, author: 1},
+				{path: []*Command{lyrics}, author: 1, bypass: true},
+				{path: []*Command{lyrics}, author: 1, bypass: true},
+				{path: []*Command{lyrics}, author: 1, err: "Slow down! You can use this command again in 10s."},
+			},
+		},
+		{
+			name:   "global bypass role",
+			global: []snowflake.ID{bypassRole},
+			uses: []use{
+				{path: []*Command{queue, show}, author: 1, bypass: true},
+				{path: []*Command{queue, show}, author: 1, bypass: true},
+				{path: []*Command{queue, show}, author: 1},
+				{path: []*Command{queue, show}, author: 1, err: "Slow down! You can use this command again in 10s."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			r := New(Options{CooldownBypassRoles: tt.global})
+			r.cooldowns.now = clock.Now
+
+			for i, use := range tt.uses {
+				clock.Advance(use.advance)
+
+				var roles []snowflake.ID
+				if use.bypass {
+					roles = []snowflake.ID{bypassRole}
+				}
+				channelID := use.channel
+				if channelID == 0 {
+					channelID = cooldownChannel
+				}
+
+				ctx := newCooldownContext(r, use.path, use.author, channelID, roles)
+				err := r.checkCooldown(ctx)
+				switch {
+				case use.err == "" && err != nil:
+					t.Fatalf("use %d: unexpected error: %v", i, err)
+				case use.err != "" && (err == nil || err.Error() != use.err):
+					t.Fatalf("use %d: got error %v, want %q", i, err, use.err)
+				case err != nil && utils.KindOf(err) != utils.KindCooldown:
+					t.Fatalf("use %d: got error kind %s, want cooldown", i, utils.KindOf(err))
+				}
+			}
+		})
+	}
+}
+
+// newCooldownContext returns a prefix invocation of path by the author, a
+// member of the test guild with roles, in the channel.
+func newCooldownContext(r *Registry, path []*Command, author, channelID snowflake.ID, roles []snowflake.ID) *Context {
+	guildID := cooldownGuild
+	return &Context{
+		registry: r,
+		path:     path,
+		messageData: &events.MessageCreate{GenericMessage: &events.GenericMessage{
+			ChannelID: channelID,
+			GuildID:   &guildID,
+			Message: discord.Message{
+				ChannelID: channelID,
+				GuildID:   &guildID,
+				Author:    discord.User{ID: author},
+				Member:    &discord.Member{RoleIDs: roles},
+			},
+		}},
+	}
+}
@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// editTracker remembers which response belongs to which invocation message,
+// so a command re-run after an edit can update its earlier response.
+type editTracker struct {
+	mu          sync.Mutex
+	window      time.Duration
+	invocations map[snowflake.ID]trackedInvocation
+	lastSweep   time.Time
+	// now is the clock the window is measured against, replaced in tests.
+	now func() time.Time
+}
+
+type trackedInvocation struct {
+	// content is what the message said when the command last ran.
+	content  string
+	response *discord.Message
+	expires  time.Time
+}
+
+func newEditTracker(window time.Duration) *editTracker {
+	return &editTracker{
+		window:      window,
+		invocations: make(map[snowflake.ID]trackedInvocation),
+		lastSweep:   time.Now(),
+		now:         time.Now,
+	}
+}
+
+// track records the content the message ran with and the response it got.
+func (t *editTracker) track(messageID snowflake.ID, content string, response *discord.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+	t.invocations[messageID] = trackedInvocation{
+		content:  content,
+		response: response,
+		expires:  now.Add(t.window),
+	}
+}
+
+// rerun claims an edited message for another run, returning the response of
+// the previous one. It reports false when the message already ran with this
+// content, like for the updates of links being unfurled, and when it's
+// neither tracked nor recent enough to be. The content is recorded before the
+// command runs so updates arriving meanwhile don't run it again.
+func (t *editTracker) rerun(messageID snowflake.ID, content string, createdAt time.Time) (*discord.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	invocation, tracked := t.invocations[messageID]
+	if tracked && invocation.expires.Before(now) {
+		tracked = false
+	}
+
+	switch {
+	case !tracked && now.Sub(createdAt) > t.window:
+		return nil, false
+	case tracked && invocation.content == content:
+		return nil, false
+	}
+
+	t.sweep(now)
+	t.invocations[messageID] = trackedInvocation{
+		content:  content,
+		response: invocation.response,
+		expires:  now.Add(t.window),
+	}
+	return invocation.response, true
+}
+
+// sweep drops the expired invocations, at most once per window. t.mu must be
+// held.
+func (t *editTracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) <= t.window {
+		return
+	}
+	for id, invocation := range t.invocations {
+		if invocation.expires.Before(now) {
+			delete(t.invocations, id)
+		}
+	}
+	t.lastSweep = now
+}
+
+// OnMessageUpdate re-runs prefix commands whose invocation was edited within
+// the edit tracking window, replacing the first response of the previous run.
+// Edited messages that weren't commands before run as new invocations.
+// Updates that don't change the content, like embeds being unfurled, are
+// ignored. The message cache isn't needed, the content of the last run is
+// tracked instead.
+func (r *Registry) OnMessageUpdate(event *events.MessageUpdate) {
+	if r.edits == nil || event.Message.Author.Bot || event.Message.EditedTimestamp == nil {
+		return
+	}
+
+	response, ok := r.edits.rerun(event.MessageID, event.Message.Content, event.Message.CreatedAt)
+	if !ok {
+		return
+	}
+
+	r.handleMessage(&events.MessageCreate{GenericMessage: event.GenericMessage}, response)
+}
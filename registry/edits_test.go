@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+func TestEditTrackerRerun(t *testing.T) {
+	const window = 5 * time.Minute
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	response := &discord.Message{ID: 99}
+
+	// Each update edits message 1, sent at start, to content at start+at.
+	// The first run is tracked with "!play a" unless untracked is set.
+	type update struct {
+		at       time.Duration
+		content  string
+		rerun    bool
+		previous *discord.Message
+	}
+	tests := []struct {
+		name      string
+		untracked bool
+		updates   []update
+	}{
+		{
+			name: "edited",
+			updates: []update{
+				{at: time.Minute, content: "!play b", rerun: true, previous: response},
+			},
+		},
+		{
+			name: "unfurled after an edit",
+			updates: []update{
+				{at: time.Minute, content: "!play b", rerun: true, previous: response},
+				{at: time.Minute + time.Second, content: "!play b"},
+			},
+		},
+		{
+			name: "same content",
+			updates: []update{
+				{at: time.Second, content: "!play a"},
+			},
+		},
+		{
+			name: "edited back",
+			updates: []update{
+				{at: time.Minute, content: "!play b", rerun: true, previous: response},
+				{at: 2 * time.Minute, content: "!play a", rerun: true, previous: response},
+			},
+		},
+		{
+			name: "each edit extends the window",
+			updates: []update{
+				{at: 4 * time.Minute, content: "!play b", rerun: true, previous: response},
+				{at: 8 * time.Minute, content: "!play c", rerun: true, previous: response},
+			},
+		},
+		{
+			name: "window expired",
+			updates: []update{
+				{at: window + time.Second, content: "!play b"},
+			},
+		},
+		{
+			name:      "wasn't a command",
+			untracked: true,
+			updates: []update{
+				{at: time.Minute, content: "!play b", rerun: true},
+				{at: time.Minute + time.Second, content: "!play b"},
+			},
+		},
+		{
+			name:      "wasn't a command and is too old",
+			untracked: true,
+			updates: []update{
+				{at: window + time.Second, content: "!play b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: start}
+			tracker := newEditTracker(window)
+			tracker.now = clock.Now
+			if !tt.untracked {
+				tracker.track(1, "!play a", response)
+			}
+
+			for i, update := range tt.updates {
+				clock.now = start.Add(update.at)
+				previous, rerun := tracker.rerun(1, update.content, start)
+				if rerun != update.rerun || previous != update.previous {
+					t.Fatalf("update %d: got rerun %v with %v, want %v with %v", i, rerun, previous, update.rerun, update.previous)
+				}
+			}
+		})
+	}
+}
+
+func TestEditTrackerSweep(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tracker := newEditTracker(time.Minute)
+	tracker.now = clock.Now
+	tracker.lastSweep = clock.now
+
+	tracker.track(1, "!play a", nil)
+	clock.Advance(2 * time.Minute)
+	tracker.track(2, "!play b", nil)
+
+	if _, ok := tracker.invocations[1]; ok {
+		t.Error("the expired invocation wasn't swept")
+	}
+	if _, ok := tracker.invocations[2]; !ok {
+		t.Error("the new invocation was swept")
+	}
+}
@@ -0,0 +1,176 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+// errorKind is how DefaultErrorFunc handles one kind of error.
+type errorKind struct {
+	// icon sets the kinds apart at a glance.
+	icon string
+
+	// level is what the error is logged at. Mistakes of the invoker are only
+	// worth a debug line, a dependency being down is worth a warning.
+	level slog.Level
+
+	// private keeps the reply ephemeral even when the error is Public.
+	private bool
+
+	// dm sends the reply to the invoker's DMs when the bot can't answer in the
+	// channel.
+	dm bool
+}
+
+var errorKinds = map[utils.ErrorKind]errorKind{
+	utils.KindBadInput:    {icon: "⚠️", level: slog.LevelDebug, dm: true},
+	utils.KindNotFound:    {icon: "🔍", level: slog.LevelDebug, dm: true},
+	utils.KindForbidden:   {icon: "🚫", level: slog.LevelInfo, dm: true},
+	utils.KindCooldown:    {icon: "⏳", level: slog.LevelDebug, private: true},
+	utils.KindUnavailable: {icon: "🔌", level: slog.LevelWarn, dm: true},
+	utils.KindInternal:    {icon: "💥", level: slog.LevelError, private: true},
+}
+
+// DefaultErrorFunc is used when Options.OnError isn't set. Errors are handled
+// by their kind, see utils.KindOf: user errors are logged at the level of
+// their kind and shown to the invoker with its icon, in their DMs when the bot
+// can't answer in the channel, except for cooldowns which are only worth an
+// ephemeral reply. Discord refusing a request for lack of permissions is a
+// forbidden error. Anything else is an internal error: it gets an incident ID
+// that is logged with the error chain, shown to the invoker so they can report
+// it and sent to Options.ErrorChannel.
+func DefaultErrorFunc(err error, ctx *Context) {
+	if ctx == nil {
+		slog.Error("Error executing command", slog.Any("error", err))
+		return
+	}
+
+	if isMissingPermissions(err) {
+		slog.Warn("Missing permissions",
+			slog.String("command", ctx.CommandPath()),
+			slog.String("channel_id", ctx.ChannelID().String()),
+			slog.Any("error", err),
+		)
+		err = &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("errors.missing_permissions")}
+	}
+
+	kind := utils.KindOf(err)
+	if kind == utils.KindInternal {
+		handleInternalError(err, ctx)
+		return
+	}
+
+	var userErr *utils.UserError
+	errors.As(err, &userErr)
+
+	handling := errorKinds[kind]
+	slog.Log(context.Background(), handling.level, "Command failed",
+		slog.String("kind", kind.String()),
+		slog.String("command", ctx.CommandPath()),
+		slog.String("author_id", ctx.Author().ID.String()),
+		slog.String("message", userErr.Message),
+	)
+
+	content := handling.icon + " " + userErr.Message
+	err = ctx.Respond(Response{
+		Content:   content,
+		Ephemeral: handling.private || !userErr.Public,
+		Reply:     true,
+	})
+	if handling.dm && isMissingPermissions(err) {
+		sendDM(ctx, content)
+	}
+}
+
+// handleInternalError logs err under a new incident ID, tells the invoker the
+// ID and reports it to the error channel.
+func handleInternalError(err error, ctx *Context) {
+	incident := utils.NewIncidentID()
+
+	attrs := []any{
+		slog.String("incident_id", incident),
+		slog.String("command", ctx.CommandPath()),
+		slog.String("author_id", ctx.Author().ID.String()),
+		slog.String("channel_id", ctx.ChannelID().String()),
+		slog.Any("error", err),
+		slog.Any("chain", utils.ErrorChain(err)),
+	}
+	if guildID := ctx.GuildID(); guildID != nil {
+		attrs = append(attrs, slog.String("guild_id", guildID.String()))
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		attrs = append(attrs, slog.String("stack", string(panicErr.Stack)))
+	}
+	handling := errorKinds[utils.KindInternal]
+	slog.Log(context.Background(), handling.level, "Internal error", attrs...)
+
+	_ = ctx.Respond(Response{
+		Content:   handling.icon + " " + ctx.T("errors.internal", incident),
+		Ephemeral: handling.private,
+		Reply:     true,
+	})
+
+	// Reporting comes second so a slow or failing error channel can't use up
+	// the interaction's three seconds to respond.
+	ctx.registry.reportIncident(ctx, incident, err)
+}
+
+// reportIncident posts the details of an internal error to the error channel.
+func (r *Registry) reportIncident(ctx *Context, incident string, err error) {
+	if r.errorChannel == 0 {
+		return
+	}
+
+	command := ctx.CommandPath()
+	if command == "" {
+		command = "-"
+	}
+	where := discord.ChannelMention(ctx.ChannelID())
+	if guildID := ctx.GuildID(); guildID != nil {
+		where += " (" + guildID.String() + ")"
+	}
+
+	message := discord.MessageCreate{
+		Embeds: []discord.Embed{
+			discord.NewEmbedBuilder().
+				SetTitle("Incident "+incident).
+				SetDescription("```\n"+utils.Truncate(strings.Join(utils.ErrorChain(err), "\n"), 4000)+"\n```").
+				SetColor(0xED4245).
+				AddField("Command", "`"+command+"`", true).
+				AddField("User", fmt.Sprintf("%s (%s)", discord.UserMention(ctx.Author().ID), ctx.Author().ID), true).
+				AddField("Channel", where, true).
+				SetTimestamp(time.Now()).
+				Build(),
+		},
+		AllowedMentions: &discord.AllowedMentions{},
+	}
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		message.Files = []*discord.File{discord.NewFile("stack.txt", "", strings.NewReader(string(panicErr.Stack)))}
+	}
+
+	if _, err := ctx.Client().Rest().CreateMessage(r.errorChannel, message); err != nil {
+		slog.Error("Failed to report incident", slog.String("incident_id", incident), slog.Any("error", err))
+	}
+}
+
+// sendDM sends content to the invoker's DMs.
+func sendDM(ctx *Context, content string) {
+	channel, err := ctx.Client().Rest().CreateDMChannel(ctx.Author().ID)
+	if err == nil {
+		_, err = ctx.Client().Rest().CreateMessage(channel.ID(), discord.MessageCreate{Content: content})
+	}
+	if err != nil {
+		slog.Warn("Failed to send error to DMs", slog.String("author_id", ctx.Author().ID.String()), slog.Any("error", err))
+	}
+}
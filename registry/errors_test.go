@@ -0,0 +1,140 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/registry/registrytest"
+	"github.com/goland-express/flexo/utils"
+)
+
+// recordHandler keeps every log record, whatever its level.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+// captureLogs sends the default logger's records to the returned handler for
+// the rest of the test.
+func captureLogs(t *testing.T) *recordHandler {
+	handler := &recordHandler{}
+	previous := slog.Default()
+	slog.SetDefault(slog.New(handler))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return handler
+}
+
+func TestDefaultErrorFunc(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		level     slog.Level
+		icon      string
+		ephemeral bool
+		incident  bool
+	}{
+		{
+			name:  "bad input",
+			err:   &utils.UserError{Message: "oops", Public: true},
+			level: slog.LevelDebug,
+			icon:  "⚠️",
+		},
+		{
+			name:  "not found",
+			err:   &utils.UserError{Kind: utils.KindNotFound, Message: "oops", Public: true},
+			level: slog.LevelDebug,
+			icon:  "🔍",
+		},
+		{
+			name:      "forbidden",
+			err:       &utils.UserError{Kind: utils.KindForbidden, Message: "oops"},
+			level:     slog.LevelInfo,
+			icon:      "🚫",
+			ephemeral: true,
+		},
+		{
+			name:      "cooldown stays private",
+			err:       &utils.UserError{Kind: utils.KindCooldown, Message: "oops", Public: true},
+			level:     slog.LevelDebug,
+			icon:      "⏳",
+			ephemeral: true,
+		},
+		{
+			name:  "unavailable",
+			err:   fmt.Errorf("player: %w", &utils.UserError{Kind: utils.KindUnavailable, Message: "oops", Public: true}),
+			level: slog.LevelWarn,
+			icon:  "🔌",
+		},
+		{
+			name:      "internal",
+			err:       errors.New("disk on fire"),
+			level:     slog.LevelError,
+			icon:      "💥",
+			ephemeral: true,
+			incident:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			h := registrytest.New(registry.Options{Prefix: "!"})
+			h.Registry.Add(&registry.Command{
+				Name:         "fail",
+				Description:  "Fail.",
+				SlashCommand: true,
+				Execute: func(ctx *registry.Context) error {
+					return tt.err
+				},
+			})
+
+			response := h.Slash("fail", nil).Last()
+			if !strings.HasPrefix(response.Content, tt.icon+" ") {
+				t.Errorf("got %q, want it to start with %s", response.Content, tt.icon)
+			}
+			if response.Ephemeral != tt.ephemeral {
+				t.Errorf("got ephemeral %v, want %v", response.Ephemeral, tt.ephemeral)
+			}
+			if incident := strings.Contains(response.Content, "report incident"); incident != tt.incident {
+				t.Errorf("got %q, want an incident ID: %v", response.Content, tt.incident)
+			}
+
+			if len(logs.records) != 1 {
+				t.Fatalf("got %d log records, want 1", len(logs.records))
+			}
+			record := logs.records[0]
+			if record.Level != tt.level {
+				t.Errorf("logged at %s, want %s", record.Level, tt.level)
+			}
+
+			var incident bool
+			record.Attrs(func(attr slog.Attr) bool {
+				incident = incident || attr.Key == "incident_id"
+				return true
+			})
+			if incident != tt.incident {
+				t.Errorf("got incident ID logged %v, want %v", incident, tt.incident)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// commandIndex maps lower-cased names to commands, separately for each way a
+// command can be invoked since a prefix alias may share a slash command's name.
+type commandIndex struct {
+	prefix      map[string]*Command
+	slash       map[string]*Command
+	contextMenu map[string]*Command
+}
+
+func newCommandIndex() *commandIndex {
+	return &commandIndex{
+		prefix:      make(map[string]*Command),
+		slash:       make(map[string]*Command),
+		contextMenu: make(map[string]*Command),
+	}
+}
+
+func contextMenuKey(commandType discord.ApplicationCommandType, name string) string {
+	return fmt.Sprintf("%d:%s", commandType, strings.ToLower(name))
+}
+
+// keys returns the entries the command takes up in each table.
+func (c *Command) keys() (prefix []string, slash []string, contextMenu []string) {
+	if c.PrefixCommand {
+		prefix = append(prefix, strings.ToLower(c.Name))
+		for _, alias := range c.Aliases {
+			prefix = append(prefix, strings.ToLower(alias))
+		}
+	}
+	if c.SlashCommand {
+		slash = append(slash, strings.ToLower(c.Name))
+	}
+	if c.UserCommand {
+		contextMenu = append(contextMenu, contextMenuKey(discord.ApplicationCommandTypeUser, c.Name))
+	}
+	if c.MessageCommand {
+		contextMenu = append(contextMenu, contextMenuKey(discord.ApplicationCommandTypeMessage, c.Name))
+	}
+	return prefix, slash, contextMenu
+}
+
+// add indexes the command, leaving the index untouched when any of its names
+// is already taken.
+func (i *commandIndex) add(cmd *Command) error {
+	if err := validateSubCommands(cmd, 0); err != nil {
+		return err
+	}
+
+	prefix, slash, contextMenu := cmd.keys()
+	tables := []struct {
+		kind    string
+		entries map[string]*Command
+		keys    []string
+	}{
+		{"prefix command", i.prefix, prefix},
+		{"slash command", i.slash, slash},
+		{"context menu command", i.contextMenu, contextMenu},
+	}
+
+	for _, table := range tables {
+		seen := make(map[string]bool, len(table.keys))
+		for _, key := range table.keys {
+			if existing, ok := table.entries[key]; ok {
+				return fmt.Errorf("%s %q: %q is already used by %q", table.kind, cmd.Name, key, existing.Name)
+			}
+			if seen[key] {
+				return fmt.Errorf("%s %q: %q is used twice", table.kind, cmd.Name, key)
+			}
+			seen[key] = true
+		}
+	}
+
+	for _, table := range tables {
+		for _, key := range table.keys {
+			table.entries[key] = cmd
+		}
+	}
+	return nil
+}
+
+func (i *commandIndex) remove(cmd *Command) {
+	for _, entries := range []map[string]*Command{i.prefix, i.slash, i.contextMenu} {
+		for key, indexed := range entries {
+			if indexed == cmd {
+				delete(entries, key)
+			}
+		}
+	}
+}
+
+// lookup finds a command by any of its names, trying prefix names and aliases
+// first, then slash and context menu names.
+func (i *commandIndex) lookup(name string) *Command {
+	key := strings.ToLower(name)
+	if cmd, ok := i.prefix[key]; ok {
+		return cmd
+	}
+	if cmd, ok := i.slash[key]; ok {
+		return cmd
+	}
+	for _, commandType := range []discord.ApplicationCommandType{discord.ApplicationCommandTypeUser, discord.ApplicationCommandTypeMessage} {
+		if cmd, ok := i.contextMenu[contextMenuKey(commandType, name)]; ok {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// maxSubCommandDepth is how deep Discord lets subcommands go: a command, its
+// subcommand groups and their subcommands.
+const maxSubCommandDepth = 2
+
+// validateSubCommands makes sure the tree has a shape both prefix and slash
+// invocations can take, at every level: no two siblings share a name or
+// alias, commands with subcommands have no options of their own and groups
+// aren't nested in groups. depth is 0 for top-level commands.
+func validateSubCommands(cmd *Command, depth int) error {
+	if len(cmd.SubCommands) == 0 {
+		return nil
+	}
+	if depth >= maxSubCommandDepth {
+		return fmt.Errorf("subcommand %q: subcommands can only be nested %d levels deep", cmd.Name, maxSubCommandDepth)
+	}
+	if len(cmd.AllOptions()) > 0 {
+		return fmt.Errorf("command %q: commands with subcommands can't have options or ask for confirmation", cmd.Name)
+	}
+
+	owners := make(map[string]string)
+	for _, sub := range cmd.SubCommands {
+		for _, name := range append([]string{sub.Name}, sub.Aliases...) {
+			key := strings.ToLower(name)
+			if owner, ok := owners[key]; ok {
+				return fmt.Errorf("subcommand %q of %q: %q is already used by %q", sub.Name, cmd.Name, key, owner)
+			}
+			owners[key] = sub.Name
+		}
+
+		if err := validateSubCommands(sub, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the top-level command with the given name or alias, ignoring
+// case, or nil when there is none.
+func (r *Registry) Get(name string) *Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.index.lookup(name)
+}
+
+// Lookup finds the command named by path, which may be a context menu name or
+// a command followed by subcommands like "queue clear". It returns the command
+// preceded by its parents, root first, or nil when there is none.
+func (r *Registry) Lookup(path string) []*Command {
+	if cmd := r.Get(path); cmd != nil {
+		return []*Command{cmd}
+	}
+
+	names := strings.Fields(path)
+	if len(names) == 0 {
+		return nil
+	}
+
+	cmd := r.Get(names[0])
+	if cmd == nil {
+		return nil
+	}
+
+	commands := []*Command{cmd}
+	for _, name := range names[1:] {
+		if cmd = cmd.SubCommand(name); cmd == nil {
+			return nil
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+// Remove unregisters the command with the given name or alias and reports
+// whether there was one. Slash and context menu commands stay visible in
+// Discord until the next RegisterSlash.
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd := r.index.lookup(name)
+	if cmd == nil {
+		return false
+	}
+
+	r.index.remove(cmd)
+	r.commands = slices.DeleteFunc(slices.Clone(r.commands), func(c *Command) bool {
+		return c == cmd
+	})
+	return true
+}
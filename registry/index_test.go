@@ -0,0 +1,275 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/registry"
+)
+
+// addPanic returns what Add panicked with, or nil.
+func addPanic(r *registry.Registry, cmd *registry.Command) (recovered any) {
+	defer func() { recovered = recover() }()
+	r.Add(cmd)
+	return nil
+}
+
+func TestAddRejectsSubCommandShapes(t *testing.T) {
+	run := func(*registry.Context) error { return nil }
+	option := discord.ApplicationCommandOptionString{Name: "target", Description: "Target"}
+
+	tests := []struct {
+		name string
+		cmd  *registry.Command
+		err  string
+	}{
+		{
+			name: "group in a group",
+			cmd: &registry.Command{Name: "policy", SubCommands: []*registry.Command{
+				{Name: "voice", SubCommands: []*registry.Command{
+					{Name: "channels", SubCommands: []*registry.Command{{Name: "add", Execute: run}}},
+				}},
+			}},
+			err: `subcommand "channels": subcommands can only be nested 2 levels deep`,
+		},
+		{
+			name: "options and subcommands",
+			cmd: &registry.Command{
+				Name:        "queue",
+				Options:     []discord.ApplicationCommandOption{option},
+				SubCommands: []*registry.Command{{Name: "show", Execute: run}},
+			},
+			err: `command "queue": commands with subcommands can't have options or ask for confirmation`,
+		},
+		{
+			name: "group with options",
+			cmd: &registry.Command{Name: "policy", SubCommands: []*registry.Command{
+				{
+					Name:        "voice",
+					Options:     []discord.ApplicationCommandOption{option},
+					SubCommands: []*registry.Command{{Name: "add", Execute: run}},
+				},
+			}},
+			err: `command "voice": commands with subcommands can't have options or ask for confirmation`,
+		},
+		{
+			name: "confirmation and subcommands",
+			cmd: &registry.Command{
+				Name:        "prefix",
+				Confirm:     "Sure?",
+				SubCommands: []*registry.Command{{Name: "reset", Execute: run}},
+			},
+			err: `command "prefix": commands with subcommands can't have options or ask for confirmation`,
+		},
+		{
+			name: "sibling names",
+			cmd: &registry.Command{Name: "queue", SubCommands: []*registry.Command{
+				{Name: "remove", Execute: run},
+				{Name: "delete", Aliases: []string{"Remove"}, Execute: run},
+			}},
+			err: `subcommand "delete" of "queue": "remove" is already used by "remove"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cmd.PrefixCommand = true
+			tt.cmd.SlashCommand = true
+
+			r := registry.New(registry.Options{})
+			recovered := addPanic(r, tt.cmd)
+			if want := "registry: " + tt.err; recovered != want {
+				t.Fatalf("got panic %v, want %q", recovered, want)
+			}
+			if r.Get(tt.cmd.Name) != nil || len(r.Commands()) != 0 {
+				t.Error("the rejected command was registered")
+			}
+		})
+	}
+}
+
+func TestAddAcceptsSubCommandShapes(t *testing.T) {
+	run := func(*registry.Context) error { return nil }
+	option := discord.ApplicationCommandOptionString{Name: "target", Description: "Target"}
+
+	r := registry.New(registry.Options{})
+	cmd := &registry.Command{
+		Name:          "policy",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		SubCommands: []*registry.Command{
+			{Name: "allow", Options: []discord.ApplicationCommandOption{option}, Execute: run},
+			{Name: "reset", Confirm: "Sure?", Execute: run},
+			{Name: "voice", SubCommands: []*registry.Command{
+				{Name: "add", Options: []discord.ApplicationCommandOption{option}, Execute: run},
+			}},
+		},
+	}
+	if recovered := addPanic(r, cmd); recovered != nil {
+		t.Fatalf("unexpected panic: %v", recovered)
+	}
+	if got := r.Lookup("policy voice add"); len(got) != 3 || got[2].Name != "add" {
+		t.Errorf("got %v, want the path to add", got)
+	}
+}
+
+func TestAddCollisions(t *testing.T) {
+	run := func(*registry.Context) error { return nil }
+	play := &registry.Command{Name: "play", Aliases: []string{"p"}, PrefixCommand: true, SlashCommand: true, Execute: run}
+	addToQueue := &registry.Command{Name: "Add to queue", MessageCommand: true, Execute: run}
+
+	tests := []struct {
+		name string
+		cmd  *registry.Command
+		err  string
+	}{
+		{
+			name: "name taken by a name",
+			cmd:  &registry.Command{Name: "Play", PrefixCommand: true, Execute: run},
+			err:  `prefix command "Play": "play" is already used by "play"`,
+		},
+		{
+			name: "alias taken by a name",
+			cmd:  &registry.Command{Name: "search", Aliases: []string{"PLAY"}, PrefixCommand: true, Execute: run},
+			err:  `prefix command "search": "play" is already used by "play"`,
+		},
+		{
+			name: "name taken by an alias",
+			cmd:  &registry.Command{Name: "p", PrefixCommand: true, Execute: run},
+			err:  `prefix command "p": "p" is already used by "play"`,
+		},
+		{
+			name: "alias repeating the name",
+			cmd:  &registry.Command{Name: "skip", Aliases: []string{"Skip"}, PrefixCommand: true, Execute: run},
+			err:  `prefix command "skip": "skip" is used twice`,
+		},
+		{
+			name: "slash name",
+			cmd:  &registry.Command{Name: "play", Description: "Play.", SlashCommand: true, Execute: run},
+			err:  `slash command "play": "play" is already used by "play"`,
+		},
+		{
+			name: "context menu name of the same type",
+			cmd:  &registry.Command{Name: "add to queue", MessageCommand: true, Execute: run},
+			err:  `context menu command "add to queue": "3:add to queue" is already used by "Add to queue"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := registry.New(registry.Options{})
+			r.Add(play)
+			r.Add(addToQueue)
+
+			recovered := addPanic(r, tt.cmd)
+			if want := "registry: " + tt.err; recovered != want {
+				t.Fatalf("got panic %v, want %q", recovered, want)
+			}
+			if len(r.Commands()) != 2 {
+				t.Errorf("got %d commands, want the rejected one left out", len(r.Commands()))
+			}
+			if got := r.Get("play"); got != play {
+				t.Errorf("play now resolves to %v", got)
+			}
+		})
+	}
+}
+
+func TestAddSharedNames(t *testing.T) {
+	run := func(*registry.Context) error { return nil }
+	slash := &registry.Command{Name: "queue", Description: "Show the queue.", SlashCommand: true, Execute: run}
+
+	tests := []struct {
+		name string
+		cmd  *registry.Command
+	}{
+		{
+			name: "prefix alias and slash name",
+			cmd:  &registry.Command{Name: "list", Aliases: []string{"queue"}, PrefixCommand: true, Execute: run},
+		},
+		{
+			name: "user command and slash name",
+			cmd:  &registry.Command{Name: "Queue", UserCommand: true, Execute: run},
+		},
+		{
+			name: "message command and slash name",
+			cmd:  &registry.Command{Name: "queue", MessageCommand: true, Execute: run},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := registry.New(registry.Options{})
+			r.Add(slash)
+			if recovered := addPanic(r, tt.cmd); recovered != nil {
+				t.Fatalf("unexpected panic: %v", recovered)
+			}
+			if len(r.Commands()) != 2 {
+				t.Errorf("got %d commands, want both", len(r.Commands()))
+			}
+		})
+	}
+
+	// A user and a message command can share a name too.
+	r := registry.New(registry.Options{})
+	r.Add(&registry.Command{Name: "Info", UserCommand: true, Execute: run})
+	if recovered := addPanic(r, &registry.Command{Name: "Info", MessageCommand: true, Execute: run}); recovered != nil {
+		t.Errorf("unexpected panic: %v", recovered)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	run := func(*registry.Context) error { return nil }
+	newRegistry := func() (*registry.Registry, *registry.Command) {
+		r := registry.New(registry.Options{})
+		queue := &registry.Command{
+			Name:          "queue",
+			Description:   "Manage the queue.",
+			Aliases:       []string{"q", "list"},
+			PrefixCommand: true,
+			SlashCommand:  true,
+			SubCommands:   []*registry.Command{{Name: "clear", Execute: run}},
+		}
+		r.Add(queue)
+		r.Add(&registry.Command{Name: "play", PrefixCommand: true, Execute: run})
+		return r, queue
+	}
+
+	for _, name := range []string{"queue", "Q", "list"} {
+		t.Run(name, func(t *testing.T) {
+			r, queue := newRegistry()
+			if !r.Remove(name) {
+				t.Fatal("found nothing to remove")
+			}
+
+			for _, key := range []string{"queue", "q", "list"} {
+				if got := r.Get(key); got != nil {
+					t.Errorf("%s still resolves to %s", key, got.Name)
+				}
+			}
+			if got := r.Lookup("queue clear"); got != nil {
+				t.Errorf("queue clear still resolves to %v", got)
+			}
+			if got := r.Commands(); len(got) != 1 || got[0].Name != "play" {
+				t.Errorf("got commands %v, want only play", got)
+			}
+			if r.Remove(name) {
+				t.Error("removed the command twice")
+			}
+
+			// Every name is free again.
+			if recovered := addPanic(r, queue); recovered != nil {
+				t.Fatalf("adding it back panicked: %v", recovered)
+			}
+			if got := r.Lookup("q clear"); len(got) != 2 || got[1].Name != "clear" {
+				t.Errorf("got %v, want the path to clear", got)
+			}
+		})
+	}
+
+	r, _ := newRegistry()
+	if r.Remove("clear") || r.Remove("stop") {
+		t.Error("removed a command that isn't registered at the top level")
+	}
+}
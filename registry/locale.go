@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/i18n"
+)
+
+// Locale returns the locale responses should use: the invoker's client
+// locale for interactions, otherwise the guild's language.
+func (c *Context) Locale() discord.Locale {
+	if c.interaction != nil {
+		return c.interaction.Locale()
+	}
+
+	if guildID := c.GuildID(); guildID != nil && c.registry.guildLocale != nil {
+		if locale := c.registry.guildLocale(*guildID); locale != "" {
+			return locale
+		}
+	}
+	return c.registry.catalog.Fallback()
+}
+
+// T translates key into the context's locale, see i18n.Catalog.T.
+func (c *Context) T(key string, args ...any) string {
+	return c.registry.catalog.T(c.Locale(), key, args...)
+}
+
+// N translates the plural form of key matching count, see i18n.Catalog.N.
+func (c *Context) N(key string, count int, args ...any) string {
+	return c.registry.catalog.N(c.Locale(), key, count, args...)
+}
+
+// Localize translates key, returning fallback when no catalog has it. It's
+// meant for texts defined in code, like command descriptions.
+func (c *Context) Localize(key, fallback string) string {
+	if text, ok := c.registry.catalog.Lookup(c.Locale(), key); ok {
+		return text
+	}
+	return fallback
+}
+
+// CommandKey is the catalog key prefix of a command's metadata, followed by
+// ".name", ".description" or ".options.<option>.name|description".
+func CommandKey(path []*Command) string {
+	names := make([]string, len(path))
+	for i, cmd := range path {
+		names[i] = cmd.Name
+	}
+	return "commands." + strings.Join(names, ".")
+}
+
+// localizeOptions fills in the name and description localizations of the
+// options from the catalog.
+func localizeOptions(options []discord.ApplicationCommandOption, key string, catalog *i18n.Catalog) []discord.ApplicationCommandOption {
+	localized := make([]discord.ApplicationCommandOption, len(options))
+	for i, option := range options {
+		optionKey := key + ".options." + option.OptionName()
+		names := catalog.Localizations(optionKey + ".name")
+		descriptions := catalog.Localizations(optionKey + ".description")
+
+		switch o := option.(type) {
+		case discord.ApplicationCommandOptionString:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionInt:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionFloat:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionBool:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionUser:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionChannel:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionRole:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionMentionable:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		case discord.ApplicationCommandOptionAttachment:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			option = o
+		}
+		localized[i] = option
+	}
+	return localized
+}
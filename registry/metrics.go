@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram, growing by
+// half from 1ms to about a minute. The last bucket takes everything slower.
+var latencyBuckets = func() []time.Duration {
+	var buckets []time.Duration
+	for bound := float64(time.Millisecond); bound < float64(time.Minute); bound *= 1.5 {
+		buckets = append(buckets, time.Duration(bound))
+	}
+	return buckets
+}()
+
+type CommandMetrics struct {
+	Invocations int64         `json:"invocations"`
+	Slash       int64         `json:"slash"`
+	Prefix      int64         `json:"prefix"`
+	Errors      int64         `json:"errors"`
+	UserErrors  int64         `json:"user_errors"`
+	Total       time.Duration `json:"total"`
+	Max         time.Duration `json:"max"`
+
+	// Guilds counts invocations per guild, direct messages aren't counted.
+	Guilds map[snowflake.ID]int64 `json:"guilds,omitempty"`
+
+	// Latencies counts invocations per latency bucket.
+	Latencies []int64 `json:"latencies"`
+}
+
+func (m CommandMetrics) Average() time.Duration {
+	if m.Invocations == 0 {
+		return 0
+	}
+	return m.Total / time.Duration(m.Invocations)
+}
+
+// Failures returns the number of unexpected errors, the ones that aren't
+// user errors.
+func (m CommandMetrics) Failures() int64 {
+	return m.Errors - m.UserErrors
+}
+
+// Percentile estimates the latency under which p percent of the invocations
+// completed, within the precision of the histogram.
+func (m CommandMetrics) Percentile(p float64) time.Duration {
+	var count int64
+	for _, n := range m.Latencies {
+		count += n
+	}
+	if count == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(count)))
+	for i, n := range m.Latencies {
+		rank -= n
+		if rank <= 0 {
+			if i < len(latencyBuckets) {
+				return min(latencyBuckets[i], m.Max)
+			}
+			break
+		}
+	}
+	return m.Max
+}
+
+func (m CommandMetrics) clone() CommandMetrics {
+	guilds := make(map[snowflake.ID]int64, len(m.Guilds))
+	for guildID, count := range m.Guilds {
+		guilds[guildID] = count
+	}
+	m.Guilds = guilds
+	m.Latencies = append([]int64(nil), m.Latencies...)
+	return m
+}
+
+// Metrics collects usage, errors and latencies per command path. Every
+// registry records into one, see Options.Metrics.
+type Metrics struct {
+	mu       sync.Mutex
+	commands map[string]*CommandMetrics
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{commands: make(map[string]*CommandMetrics)}
+}
+
+// LoadMetrics restores metrics saved with Save, a missing file is treated as
+// empty.
+func LoadMetrics(path string) (*Metrics, error) {
+	m := NewMetrics()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &m.commands); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	return m, nil
+}
+
+// Middleware records every command it wraps. Registries already record the
+// commands they run, it's only needed for metrics kept apart from those. The
+// time spent waiting for the invoker to confirm isn't counted as latency.
+func (m *Metrics) Middleware() Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx *Context) error {
+			start := time.Now()
+			err := next(ctx)
+			m.record(ctx, time.Since(start)-ctx.confirmWait, err)
+			return err
+		}
+	}
+}
+
+func (m *Metrics) record(ctx *Context, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	command := ctx.CommandPath()
+	metrics, ok := m.commands[command]
+	if !ok {
+		metrics = &CommandMetrics{}
+		m.commands[command] = metrics
+	}
+
+	metrics.Invocations++
+	if ctx.IsSlash() {
+		metrics.Slash++
+	} else {
+		metrics.Prefix++
+	}
+
+	if guildID := ctx.GuildID(); guildID != nil {
+		if metrics.Guilds == nil {
+			metrics.Guilds = make(map[snowflake.ID]int64)
+		}
+		metrics.Guilds[*guildID]++
+	}
+
+	metrics.Total += duration
+	metrics.Max = max(metrics.Max, duration)
+	if len(metrics.Latencies) != len(latencyBuckets)+1 {
+		metrics.Latencies = make([]int64, len(latencyBuckets)+1)
+	}
+	bucket := len(latencyBuckets)
+	for i, bound := range latencyBuckets {
+		if duration <= bound {
+			bucket = i
+			break
+		}
+	}
+	metrics.Latencies[bucket]++
+
+	if err != nil {
+		metrics.Errors++
+		if utils.KindOf(err) != utils.KindInternal {
+			metrics.UserErrors++
+		}
+	}
+}
+
+func (m *Metrics) Snapshot() map[string]CommandMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]CommandMetrics, len(m.commands))
+	for command, metrics := range m.commands {
+		snapshot[command] = metrics.clone()
+	}
+	return snapshot
+}
+
+// Command returns the metrics of one command path, like "queue remove".
+func (m *Metrics) Command(path string) (CommandMetrics, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics, ok := m.commands[path]
+	if !ok {
+		return CommandMetrics{}, false
+	}
+	return metrics.clone(), true
+}
+
+// Save writes the metrics to path as JSON, see utils.WriteFileAtomic.
+func (m *Metrics) Save(path string) error {
+	m.mu.Lock()
+	data, err := json.MarshalIndent(m.commands, "", "  ")
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to save metrics: %w", err)
+	}
+	return nil
+}
+
+// SaveEvery saves the metrics to path on every interval until ctx is done,
+// then one last time.
+func (m *Metrics) SaveEvery(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Save(path); err != nil {
+				slog.Error("Failed to save metrics", slog.Any("error", err))
+			}
+		case <-ctx.Done():
+			if err := m.Save(path); err != nil {
+				slog.Error("Failed to save metrics", slog.Any("error", err))
+			}
+			return
+		}
+	}
+}
+
+// Metrics returns the usage metrics of the registry's commands.
+func (r *Registry) Metrics() *Metrics {
+	return r.metrics
+}
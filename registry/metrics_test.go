@@ -0,0 +1,40 @@
+package registry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/registry/registrytest"
+)
+
+func TestMetricsExcludeConfirmWait(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+
+	h := registrytest.New(registry.Options{Prefix: "!", ConfirmTimeout: timeout})
+	h.Registry.Add(&registry.Command{
+		Name:          "wipe",
+		Description:   "Wipe everything.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		Confirm:       "Wipe everything?",
+		Execute: func(ctx *registry.Context) error {
+			return ctx.Say("wiped")
+		},
+	})
+
+	// Nobody answers, so both invocations wait for the whole timeout.
+	for _, result := range []*registrytest.Result{h.Prefix("!wipe"), h.Slash("wipe", nil)} {
+		if result.Err != nil {
+			t.Fatalf("unexpected error: %v", result.Err)
+		}
+	}
+
+	metrics, ok := h.Registry.Metrics().Command("wipe")
+	if !ok || metrics.Invocations != 2 {
+		t.Fatalf("got %+v, want two invocations recorded", metrics)
+	}
+	if metrics.Max >= timeout {
+		t.Errorf("got max latency %s, want the %s spent waiting for an answer left out", metrics.Max, timeout)
+	}
+}
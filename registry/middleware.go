@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+// Middleware wraps command execution. Middlewares registered with
+// Registry.Use run for every command in registration order, inside the one
+// recording metrics, and the ones declared on a command run inside them.
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
+func (r *Registry) Use(middlewares ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+func (r *Registry) chain(handler ExecuteFunc, path []*Command) ExecuteFunc {
+	r.mu.RLock()
+	middlewares := make([]Middleware, 0, len(r.middlewares)+1)
+	middlewares = append(middlewares, r.metrics.Middleware())
+	middlewares = append(middlewares, r.middlewares...)
+	r.mu.RUnlock()
+
+	for _, cmd := range path {
+		middlewares = append(middlewares, cmd.Middlewares...)
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recover turns panics into a *PanicError so they reach OnError instead of
+// crashing the event handler.
+func Recover() Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx *Context) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					err = &PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+			return next(ctx)
+		}
+	}
+}
+
+func Logger(logger *slog.Logger) Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx *Context) error {
+			start := time.Now()
+			err := next(ctx)
+
+			attrs := []slog.Attr{
+				slog.String("command", ctx.CommandPath()),
+				slog.Bool("slash", ctx.IsSlash()),
+				slog.String("author_id", ctx.Author().ID.String()),
+				slog.String("channel_id", ctx.ChannelID().String()),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if guildID := ctx.GuildID(); guildID != nil {
+				attrs = append(attrs, slog.String("guild_id", guildID.String()))
+			}
+
+			var userErr *utils.UserError
+			switch {
+			case err == nil:
+				logger.LogAttrs(context.Background(), slog.LevelInfo, "Command executed", attrs...)
+			case errors.As(err, &userErr):
+				logger.LogAttrs(context.Background(), slog.LevelInfo, "Command rejected", append(attrs, slog.String("reason", userErr.Message))...)
+			default:
+				var panicErr *PanicError
+				if errors.As(err, &panicErr) {
+					attrs = append(attrs, slog.String("stack", string(panicErr.Stack)))
+				}
+				logger.LogAttrs(context.Background(), slog.LevelError, "Command failed", append(attrs, slog.Any("error", err))...)
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+type ModalFunc func(ctx *ModalContext) error
+
+// ModalHandler handles submitted modals whose custom ID starts with Prefix.
+// Modals are opened with Context.OpenModal, using CustomID to build their ID.
+type ModalHandler struct {
+	Prefix  string
+	Checks  []CheckFunc
+	Execute ModalFunc
+
+	// Command is the path of the command the modal belongs to, see
+	// ComponentHandler.Command.
+	Command string
+}
+
+type ModalContext struct {
+	*Context
+	event  *events.ModalSubmitInteractionCreate
+	params []string
+}
+
+func (c *ModalContext) Event() *events.ModalSubmitInteractionCreate {
+	return c.event
+}
+
+func (c *ModalContext) CustomID() string {
+	return c.event.Data.CustomID
+}
+
+func (c *ModalContext) Params() []string {
+	return c.params
+}
+
+// Param returns the i-th custom ID parameter, or an empty string when there
+// aren't that many.
+func (c *ModalContext) Param(i int) string {
+	if i < 0 || i >= len(c.params) {
+		return ""
+	}
+	return c.params[i]
+}
+
+// Text returns the trimmed value of the text input with the given custom ID.
+func (c *ModalContext) Text(id string) string {
+	return strings.TrimSpace(c.event.Data.Text(id))
+}
+
+// OptText is like Text but reports whether the input was filled in.
+func (c *ModalContext) OptText(id string) (string, bool) {
+	value, ok := c.event.Data.OptText(id)
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
+
+// Int parses the text input as an integer. Empty or invalid values result in
+// a UserError naming the input's label.
+func (c *ModalContext) Int(id string) (int64, error) {
+	value, ok := c.OptText(id)
+	if !ok {
+		return 0, &utils.UserError{Message: c.T("modals.required", c.label(id))}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &utils.UserError{Message: c.T("modals.not_integer", c.label(id))}
+	}
+	return n, nil
+}
+
+// Float parses the text input as a number, accepting a decimal comma. Empty or
+// invalid values result in a UserError naming the input's label.
+func (c *ModalContext) Float(id string) (float64, error) {
+	value, ok := c.OptText(id)
+	if !ok {
+		return 0, &utils.UserError{Message: c.T("modals.required", c.label(id))}
+	}
+
+	n, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, &utils.UserError{Message: c.T("modals.not_number", c.label(id))}
+	}
+	return n, nil
+}
+
+func (c *ModalContext) label(id string) string {
+	if input, ok := c.event.Data.TextInputComponent(id); ok && input.Label != "" {
+		return input.Label
+	}
+	return id
+}
+
+// UpdateMessage edits the message holding the component that opened the modal.
+func (c *ModalContext) UpdateMessage(update discord.MessageUpdate) error {
+	if err := c.initialResponse(discord.InteractionResponseTypeUpdateMessage, update); err != nil {
+		return fmt.Errorf("failed to update modal message: %w", err)
+	}
+	return nil
+}
+
+// OpenModal shows a modal in response to the interaction. Modals can't be
+// opened from prefix commands and have to be the first response.
+func (c *Context) OpenModal(modal discord.ModalCreate) error {
+	if c.respond == nil {
+		return &utils.UserError{Kind: utils.KindUnavailable, Message: c.T("modals.slash_only")}
+	}
+
+	if err := c.initialResponse(discord.InteractionResponseTypeModal, modal); err != nil {
+		return fmt.Errorf("failed to open modal: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) AddModal(handler *ModalHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modals[handler.Prefix] = handler
+}
+
+func (r *Registry) OnModalSubmit(event *events.ModalSubmitInteractionCreate) {
+	parts := strings.Split(event.Data.CustomID, customIDSeparator)
+
+	r.mu.RLock()
+	handler, ok := r.modals[parts[0]]
+	r.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	ctx := &ModalContext{
+		Context: &Context{
+			registry:    r,
+			client:      event.Client(),
+			interaction: event.ModalSubmitInteraction,
+			respond:     event.Respond,
+			data:        r.data,
+		},
+		event:  event,
+		params: parts[1:],
+	}
+
+	if err := r.checkOwner(ctx.Context, handler.Command); err != nil {
+		r.onError(err, ctx.Context)
+		return
+	}
+
+	for _, check := range handler.Checks {
+		if err := check(ctx.Context); err != nil {
+			r.onError(err, ctx.Context)
+			return
+		}
+	}
+
+	execute := Recover()(func(*Context) error {
+		return handler.Execute(ctx)
+	})
+	if err := execute(ctx.Context); err != nil {
+		r.onError(err, ctx.Context)
+	}
+}
@@ -0,0 +1,271 @@
+package registry
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+const (
+	paginatorPrefix         = "paginator"
+	defaultPaginatorTimeout = 5 * time.Minute
+)
+
+// PageFunc renders the page with the given index, starting at 0.
+type PageFunc func(page int) (discord.Embed, error)
+
+// Paginator shows one page of a long list at a time, with buttons to go to the
+// first, previous, next and last page and to jump to any page.
+type Paginator struct {
+	Pages int
+	Page  PageFunc
+
+	// Start is the index of the page shown first.
+	Start int
+
+	// AuthorOnly lets only the invoker turn the pages.
+	AuthorOnly bool
+
+	// Timeout is how long the buttons stay usable after the pages were last
+	// turned, they are disabled afterwards. Defaults to 5 minutes.
+	Timeout time.Duration
+
+	// Ephemeral makes the pages only visible to the invoker, see
+	// Response.Ephemeral.
+	Ephemeral bool
+}
+
+type paginatorSession struct {
+	Paginator
+
+	mu       sync.Mutex
+	id       string
+	authorID snowflake.ID
+	page     int
+	edit     func(discord.MessageUpdate) error
+	timer    *time.Timer
+}
+
+// Paginate sends the start page with buttons to turn the pages, a single page
+// is sent without them. An invocation has one paginator, paginating again
+// replaces the previous one, which lets an edited prefix command take over
+// its response.
+func (c *Context) Paginate(p Paginator) error {
+	p.Pages = max(p.Pages, 1)
+	p.Start = min(max(p.Start, 0), p.Pages-1)
+	if p.Timeout <= 0 {
+		p.Timeout = defaultPaginatorTimeout
+	}
+
+	embed, err := p.Page(p.Start)
+	if err != nil {
+		return err
+	}
+
+	if p.Pages == 1 {
+		return c.Respond(Response{Embeds: []discord.Embed{embed}, Ephemeral: p.Ephemeral})
+	}
+
+	s := &paginatorSession{
+		Paginator: p,
+		id:        c.invocationID().String(),
+		authorID:  c.Author().ID,
+		page:      p.Start,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	edit, err := c.send(c.messageCreate(Response{
+		Embeds:     []discord.Embed{embed},
+		Components: s.controls(c, false),
+		Ephemeral:  p.Ephemeral,
+	}))
+	if err != nil {
+		return err
+	}
+
+	s.edit = edit
+	c.registry.addPaginator(s)
+	s.timer = time.AfterFunc(p.Timeout, func() {
+		c.registry.expirePaginator(c, s)
+	})
+	return nil
+}
+
+// invocationID identifies the message or interaction that created the context.
+func (c *Context) invocationID() snowflake.ID {
+	if c.interaction != nil {
+		return c.interaction.ID()
+	}
+	return c.messageData.Message.ID
+}
+
+func (s *paginatorSession) controls(ctx *Context, disabled bool) []discord.ContainerComponent {
+	last := s.Pages - 1
+	button := func(label, action string, enabled bool) discord.ButtonComponent {
+		return discord.NewSecondaryButton(label, CustomID(paginatorPrefix, s.id, action)).WithDisabled(disabled || !enabled)
+	}
+
+	return []discord.ContainerComponent{
+		discord.NewActionRow(
+			button("⏮", "first", s.page > 0),
+			button("◀", "prev", s.page > 0),
+			button(ctx.T("paginator.page", s.page+1, s.Pages), "jump", true),
+			button("▶", "next", s.page < last),
+			button("⏭", "last", s.page < last),
+		),
+	}
+}
+
+func (r *Registry) addPaginator(s *paginatorSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if previous, ok := r.paginators[s.id]; ok {
+		previous.timer.Stop()
+	}
+	r.paginators[s.id] = s
+}
+
+func (r *Registry) paginator(id string) *paginatorSession {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.paginators[id]
+}
+
+// expirePaginator disables the buttons of a paginator that timed out.
+func (r *Registry) expirePaginator(ctx *Context, s *paginatorSession) {
+	r.mu.Lock()
+	if r.paginators[s.id] == s {
+		delete(r.paginators, s.id)
+	}
+	r.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.edit(discord.MessageUpdate{Components: utils.Ptr(s.controls(ctx, true))})
+	if err != nil {
+		slog.Warn("Failed to disable paginator", slog.String("paginator", s.id), slog.Any("error", err))
+	}
+}
+
+// turn renders the page for a component or modal interaction on the
+// paginator's message, which from then on is edited through that interaction.
+func (s *paginatorSession) turn(ctx *Context, page int, update func(discord.MessageUpdate) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page = min(max(page, 0), s.Pages-1)
+	embed, err := s.Page(page)
+	if err != nil {
+		return err
+	}
+	s.page = page
+
+	if err := update(discord.MessageUpdate{
+		Embeds:     &[]discord.Embed{embed},
+		Components: utils.Ptr(s.controls(ctx, false)),
+	}); err != nil {
+		return err
+	}
+
+	s.edit = ctx.EditResponse
+	s.timer.Reset(s.Timeout)
+	return nil
+}
+
+func (s *paginatorSession) checkAuthor(ctx *Context) error {
+	if s.AuthorOnly && ctx.Author().ID != s.authorID {
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("paginator.author_only", discord.UserMention(s.authorID))}
+	}
+	return nil
+}
+
+// paginatorHandlers route the buttons of every paginator. Expired paginators
+// are disabled by their own timers, so the component timeout doesn't apply.
+func (r *Registry) paginatorHandlers() (*ComponentHandler, *ModalHandler) {
+	component := &ComponentHandler{
+		Prefix:  paginatorPrefix,
+		Timeout: math.MaxInt64,
+		Execute: r.handlePaginator,
+	}
+	modal := &ModalHandler{
+		Prefix:  paginatorPrefix,
+		Execute: r.submitPaginatorJump,
+	}
+	return component, modal
+}
+
+func (r *Registry) handlePaginator(ctx *ComponentContext) error {
+	s := r.paginator(ctx.Param(0))
+	if s == nil {
+		// The paginator was lost in a restart.
+		return ctx.UpdateMessage(discord.MessageUpdate{
+			Components: utils.Ptr(DisableComponents(ctx.Message().Components)),
+		})
+	}
+	if err := s.checkAuthor(ctx.Context); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	page := s.page
+	s.mu.Unlock()
+
+	switch ctx.Param(1) {
+	case "first":
+		page = 0
+	case "prev":
+		page--
+	case "next":
+		page++
+	case "last":
+		page = s.Pages - 1
+	case "jump":
+		return ctx.OpenModal(discord.NewModalCreateBuilder().
+			SetCustomID(CustomID(paginatorPrefix, s.id)).
+			SetTitle(ctx.T("paginator.jump.title")).
+			AddActionRow(discord.TextInputComponent{
+				CustomID:    "page",
+				Style:       discord.TextInputStyleShort,
+				Label:       ctx.T("paginator.jump.label", s.Pages),
+				MaxLength:   len(fmt.Sprint(s.Pages)),
+				Required:    true,
+				Placeholder: fmt.Sprint(page + 1),
+			}).
+			Build())
+	default:
+		return fmt.Errorf("unknown paginator action %q", ctx.Param(1))
+	}
+
+	return s.turn(ctx.Context, page, ctx.UpdateMessage)
+}
+
+func (r *Registry) submitPaginatorJump(ctx *ModalContext) error {
+	s := r.paginator(ctx.Param(0))
+	if s == nil {
+		return &utils.UserError{Kind: utils.KindUnavailable, Message: ctx.T("paginator.expired")}
+	}
+	if err := s.checkAuthor(ctx.Context); err != nil {
+		return err
+	}
+
+	page, err := ctx.Int("page")
+	if err != nil {
+		return err
+	}
+	if page < 1 || page > int64(s.Pages) {
+		return &utils.UserError{Message: ctx.T("paginator.invalid_page", s.Pages)}
+	}
+
+	return s.turn(ctx.Context, int(page-1), ctx.UpdateMessage)
+}
@@ -0,0 +1,209 @@
+package registry
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+// CacheFlags are the caches the registry resolves permissions from: the guild
+// for its owner, roles, channels with their overwrites, members for the bot's
+// own and voice states. Clients have to enable at least these.
+const CacheFlags = cache.FlagGuilds | cache.FlagMembers | cache.FlagRoles | cache.FlagChannels | cache.FlagVoiceStates
+
+const (
+	errorCodeMissingAccess      rest.JSONErrorCode = 50001
+	errorCodeMissingPermissions rest.JSONErrorCode = 50013
+)
+
+// checkAvailability holds prefix invocations to what Discord enforces for
+// application commands: the contexts, NSFW flag and default member
+// permissions of the top-level command, resolved from the cache. The bot also
+// has to be able to answer in the channel.
+func (r *Registry) checkAvailability(ctx *Context) error {
+	if ctx.interaction != nil {
+		return nil
+	}
+
+	root := ctx.path[0]
+	guildID := ctx.GuildID()
+
+	if len(root.Contexts) > 0 {
+		if guildID == nil && !slices.Contains(root.Contexts, discord.InteractionContextTypeBotDM) {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.guild_only")}
+		}
+		if guildID != nil && !slices.Contains(root.Contexts, discord.InteractionContextTypeGuild) {
+			return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.dm_only")}
+		}
+	}
+	if guildID == nil {
+		return nil
+	}
+
+	if root.NSFW && !ctx.channelNSFW() {
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.nsfw")}
+	}
+
+	if missing := root.DefaultMemberPermissions &^ ctx.Permissions(); missing != 0 {
+		return &utils.UserError{Kind: utils.KindForbidden, Message: ctx.T("checks.has_permission", PermissionNames(missing))}
+	}
+
+	send := discord.PermissionSendMessages
+	if ctx.inThread() {
+		send = discord.PermissionSendMessagesInThreads
+	}
+	return BotHasPermission(send | discord.PermissionEmbedLinks)(ctx)
+}
+
+// BotPermissions returns the bot's permissions in the current channel.
+// Interactions carry them, prefix invocations resolve them from the cache. ok
+// is false outside of guilds and when the cache doesn't know them.
+func (c *Context) BotPermissions() (discord.Permissions, bool) {
+	if c.interaction != nil {
+		if permissions := c.interaction.AppPermissions(); permissions != nil && c.GuildID() != nil {
+			return *permissions, true
+		}
+	}
+	return c.BotPermissionsIn(c.ChannelID())
+}
+
+// BotPermissionsIn resolves the bot's permissions in a channel of the current
+// guild from the cache. Threads have the permissions of their parent.
+func (c *Context) BotPermissionsIn(channelID snowflake.ID) (discord.Permissions, bool) {
+	guildID := c.GuildID()
+	if guildID == nil {
+		return discord.PermissionsNone, false
+	}
+
+	member, ok := c.selfMember(*guildID)
+	if !ok {
+		return discord.PermissionsNone, false
+	}
+	channel, ok := c.guildChannel(channelID)
+	if !ok {
+		return discord.PermissionsNone, false
+	}
+	return c.client.Caches().MemberPermissionsInChannel(channel, member), true
+}
+
+// selfMember returns the bot's member in the guild. It is cached from the
+// guild's create event, members missing from the cache are fetched and cached.
+func (c *Context) selfMember(guildID snowflake.ID) (discord.Member, bool) {
+	if member, ok := c.client.Caches().Member(guildID, c.client.ApplicationID()); ok {
+		return member, true
+	}
+
+	member, err := c.client.Rest().GetMember(guildID, c.client.ApplicationID())
+	if err != nil {
+		slog.Warn("Failed to fetch the bot's member", slog.String("guild_id", guildID.String()), slog.Any("error", err))
+		return discord.Member{}, false
+	}
+	c.client.Caches().AddMember(*member)
+	return *member, true
+}
+
+// guildChannel looks the channel up in the cache, threads resolve to their
+// parent.
+func (c *Context) guildChannel(channelID snowflake.ID) (discord.GuildChannel, bool) {
+	channel, ok := c.client.Caches().Channel(channelID)
+	if !ok {
+		return nil, false
+	}
+	if thread, isThread := channel.(discord.GuildThread); isThread && thread.ParentID() != nil {
+		return c.client.Caches().Channel(*thread.ParentID())
+	}
+	return channel, true
+}
+
+// ParentChannelID returns the channel the command was used in, or the parent
+// channel when that's a thread, so settings made for a channel cover its
+// threads too.
+func (c *Context) ParentChannelID() snowflake.ID {
+	if channel, ok := c.guildChannel(c.ChannelID()); ok {
+		return channel.ID()
+	}
+	return c.ChannelID()
+}
+
+func (c *Context) inThread() bool {
+	channel, ok := c.client.Caches().Channel(c.ChannelID())
+	if !ok {
+		return false
+	}
+	_, isThread := channel.(discord.GuildThread)
+	return isThread
+}
+
+// channelNSFW reports whether the current channel, or the parent of the
+// current thread, is age-restricted. Unknown channels aren't.
+func (c *Context) channelNSFW() bool {
+	channel, ok := c.guildChannel(c.ChannelID())
+	if !ok {
+		return false
+	}
+	nsfw, ok := channel.(interface{ NSFW() bool })
+	return ok && nsfw.NSFW()
+}
+
+// BotHasPermission requires the bot to have the permissions in the current
+// channel, naming the missing ones. It passes when they can't be resolved,
+// leaving it to Discord.
+func BotHasPermission(permissions discord.Permissions) CheckFunc {
+	return func(ctx *Context) error {
+		have, ok := ctx.BotPermissions()
+		if !ok {
+			return nil
+		}
+		return botMissing(ctx, permissions&^have, ctx.ChannelID())
+	}
+}
+
+// BotHasVoicePermission requires the bot to have the permissions in the
+// author's voice channel, like Connect and Speak to play in it.
+func BotHasVoicePermission(permissions discord.Permissions) CheckFunc {
+	return func(ctx *Context) error {
+		if err := InVoiceChannel()(ctx); err != nil {
+			return err
+		}
+
+		channelID, _ := ctx.VoiceChannelID()
+		have, ok := ctx.BotPermissionsIn(channelID)
+		if !ok {
+			return nil
+		}
+		return botMissing(ctx, permissions&^have, channelID)
+	}
+}
+
+func botMissing(ctx *Context, missing discord.Permissions, channelID snowflake.ID) error {
+	if missing == 0 {
+		return nil
+	}
+	return &utils.UserError{
+		Kind:    utils.KindForbidden,
+		Message: ctx.T("checks.bot_permission", PermissionNames(missing), discord.ChannelMention(channelID)),
+	}
+}
+
+// PermissionNames lists the names of the permissions in alphabetical order.
+func PermissionNames(permissions discord.Permissions) string {
+	names := strings.Split(permissions.String(), ", ")
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// isMissingPermissions reports whether Discord refused a request because the
+// bot lacks access or permissions.
+func isMissingPermissions(err error) bool {
+	var restErr rest.Error
+	return errors.As(err, &restErr) &&
+		(restErr.Code == errorCodeMissingAccess || restErr.Code == errorCodeMissingPermissions)
+}
@@ -0,0 +1,59 @@
+package registry_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/registry/registrytest"
+	"github.com/goland-express/flexo/utils"
+)
+
+func TestBotHasVoicePermission(t *testing.T) {
+	tests := []struct {
+		name    string
+		bot     discord.Permissions
+		missing string
+	}{
+		{name: "granted", bot: discord.PermissionConnect | discord.PermissionSpeak},
+		{name: "no connect", bot: discord.PermissionSpeak, missing: "`Connect`"},
+		{name: "neither", missing: "`Connect, Speak`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The harness caches with registry.CacheFlags, like the bot does.
+			h := registrytest.New(registry.Options{Prefix: "!"})
+			h.Registry.Add(&registry.Command{
+				Name:          "join",
+				Description:   "Join the voice channel.",
+				PrefixCommand: true,
+				SlashCommand:  true,
+				Checks:        []registry.CheckFunc{registry.BotHasVoicePermission(discord.PermissionConnect | discord.PermissionSpeak)},
+				Execute: func(ctx *registry.Context) error {
+					return ctx.Say("joined")
+				},
+			})
+			h.BotPermissions = discord.PermissionSendMessages | discord.PermissionEmbedLinks | tt.bot
+			h.JoinVoice(h.Author.ID, 300)
+
+			for _, result := range []*registrytest.Result{h.Prefix("!join"), h.Slash("join", nil)} {
+				if tt.missing == "" {
+					if result.Err != nil || result.Last().Content != "joined" {
+						t.Fatalf("got %q, %v, want the command to run", result.Last().Content, result.Err)
+					}
+					continue
+				}
+
+				if kind := utils.KindOf(result.Err); kind != utils.KindForbidden {
+					t.Fatalf("got error kind %s, want forbidden: %v", kind, result.Err)
+				}
+				if content := result.Last().Content; !strings.Contains(content, tt.missing) || !strings.Contains(content, "<#300>") {
+					t.Errorf("got %q, want it to name %s in <#300>", content, tt.missing)
+				}
+			}
+		})
+	}
+}
@@ -3,148 +3,398 @@ package registry
 import (
 	"fmt"
 	"log/slog"
-	"slices"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/i18n"
+	"github.com/goland-express/flexo/utils"
 )
 
 type Registry struct {
 	commands []*Command
+	index    *commandIndex
 	data     Data
 	prefix   string
+	owners   []snowflake.ID
 	onError  func(err error, ctx *Context)
 	onReady  func(event *events.Ready)
 	mu       sync.RWMutex
+
+	modules             []string
+	loadingModule       string
+	middlewares         []Middleware
+	cooldowns           *cooldowns
+	cooldownBypassRoles []snowflake.ID
+	components          map[string]*ComponentHandler
+	componentTimeout    time.Duration
+	modals              map[string]*ModalHandler
+	devGuilds           []snowflake.ID
+	prefixResolver      PrefixResolver
+	mentionPrefix       bool
+	edits               *editTracker
+	suggestCommands     func(guildID *snowflake.ID) bool
+	catalog             *i18n.Catalog
+	guildLocale         func(guildID snowflake.ID) discord.Locale
+	checks              []CheckFunc
+	metrics             *Metrics
+	paginators          map[string]*paginatorSession
+	confirmations       map[string]*confirmation
+	confirmTimeout      time.Duration
+	errorChannel        snowflake.ID
 }
 
+// PrefixResolver returns the prefixes a message may start with, usually
+// looked up from the settings of the guild it was sent in.
+type PrefixResolver func(event *events.MessageCreate) []string
+
 type Options struct {
 	Commands []*Command
 	Data     Data
 	Prefix   string
+	Owners   []snowflake.ID
 	OnError  func(err error, ctx *Context)
 	OnReady  func(event *events.Ready)
+
+	// CooldownBypassRoles lets members with any of these roles skip every
+	// command cooldown.
+	CooldownBypassRoles []snowflake.ID
+
+	// ComponentTimeout is how long components stay usable after their message
+	// was last updated, unless the handler sets its own. Defaults to 15 minutes.
+	ComponentTimeout time.Duration
+
+	// DevGuilds registers the slash commands in these guilds instead of
+	// globally, where changes show up immediately. Global commands registered
+	// before are deleted.
+	DevGuilds []snowflake.ID
+
+	// PrefixResolver replaces Prefix when set.
+	PrefixResolver PrefixResolver
+
+	// MentionPrefix lets messages starting with a mention of the bot invoke
+	// commands.
+	MentionPrefix bool
+
+	// EditTracking re-runs prefix commands edited within this window and
+	// edits their response instead of sending a new one, see OnMessageUpdate.
+	// Disabled when zero.
+	EditTracking time.Duration
+
+	// SuggestCommands reports whether unknown prefix commands get a "did you
+	// mean" reply in the guild, nil for direct messages. Suggestions are off
+	// when it's not set.
+	SuggestCommands func(guildID *snowflake.ID) bool
+
+	// Catalog translates command metadata and responses, defaults to the
+	// embedded catalogs with en-US as fallback.
+	Catalog *i18n.Catalog
+
+	// GuildLocale returns the language prefix commands respond with in the
+	// guild, or an empty locale to use the catalog's fallback.
+	GuildLocale func(guildID snowflake.ID) discord.Locale
+
+	// Checks run before the checks of every command, including context menu
+	// commands.
+	Checks []CheckFunc
+
+	// ConfirmTimeout is how long Context.Confirm waits for an answer, defaults
+	// to 30 seconds.
+	ConfirmTimeout time.Duration
+
+	// ErrorChannel receives the details of every internal error, see
+	// DefaultErrorFunc. Disabled when zero.
+	ErrorChannel snowflake.ID
+
+	// Metrics records the usage of every command, see Registry.Metrics.
+	// Defaults to empty metrics that aren't persisted.
+	Metrics *Metrics
 }
 
 func New(opts Options) *Registry {
 	if opts.OnError == nil {
-		opts.OnError = defaultErrorFunc
+		opts.OnError = DefaultErrorFunc
 	}
 
-	return &Registry{
-		commands: opts.Commands,
-		data:     opts.Data,
-		prefix:   opts.Prefix,
-		onError:  opts.OnError,
-		onReady:  opts.OnReady,
+	if opts.ComponentTimeout == 0 {
+		opts.ComponentTimeout = defaultComponentTimeout
 	}
-}
 
-func defaultErrorFunc(err error, ctx *Context) {
-	slog.Error("Error executing command", slog.Any("error", err))
-	if ctx != nil {
-		_ = ctx.Say("An error occurred while executing the command.")
+	if opts.ConfirmTimeout == 0 {
+		opts.ConfirmTimeout = defaultConfirmTimeout
+	}
+
+	if opts.Catalog == nil {
+		opts.Catalog = i18n.MustEmbedded(discord.LocaleEnglishUS)
+	}
+
+	if opts.Metrics == nil {
+		opts.Metrics = NewMetrics()
+	}
+
+	var edits *editTracker
+	if opts.EditTracking > 0 {
+		edits = newEditTracker(opts.EditTracking)
+	}
+
+	r := &Registry{
+		index:   newCommandIndex(),
+		data:    opts.Data,
+		prefix:  opts.Prefix,
+		owners:  opts.Owners,
+		onError: opts.OnError,
+		onReady: opts.OnReady,
+
+		cooldowns:           newCooldowns(),
+		cooldownBypassRoles: opts.CooldownBypassRoles,
+		components:          make(map[string]*ComponentHandler),
+		componentTimeout:    opts.ComponentTimeout,
+		modals:              make(map[string]*ModalHandler),
+		devGuilds:           opts.DevGuilds,
+		prefixResolver:      opts.PrefixResolver,
+		mentionPrefix:       opts.MentionPrefix,
+		edits:               edits,
+		suggestCommands:     opts.SuggestCommands,
+		catalog:             opts.Catalog,
+		guildLocale:         opts.GuildLocale,
+		checks:              opts.Checks,
+		metrics:             opts.Metrics,
+		paginators:          make(map[string]*paginatorSession),
+		confirmations:       make(map[string]*confirmation),
+		confirmTimeout:      opts.ConfirmTimeout,
+		errorChannel:        opts.ErrorChannel,
+	}
+
+	paginatorComponent, paginatorModal := r.paginatorHandlers()
+	r.AddComponent(paginatorComponent)
+	r.AddModal(paginatorModal)
+	r.AddComponent(&ComponentHandler{Prefix: confirmPrefix, Execute: r.handleConfirm})
+
+	for _, cmd := range opts.Commands {
+		r.Add(cmd)
 	}
+	return r
 }
 
 func (r *Registry) OnMessage(event *events.MessageCreate) {
+	r.handleMessage(event, nil)
+}
+
+// handleMessage runs the command in the message. A previous response is edited
+// by the first message the command sends instead of creating a new one.
+func (r *Registry) handleMessage(event *events.MessageCreate, previous *discord.Message) {
 	if event.Message.Author.Bot {
 		return
 	}
 
-	content := event.Message.Content
-	if !strings.HasPrefix(content, r.prefix) {
+	prefix, content, ok := r.matchPrefix(event)
+	if !ok {
 		return
 	}
 
-	commandName := strings.TrimPrefix(content, r.prefix)
-	if idx := strings.Index(commandName, " "); idx != -1 {
-		commandName = commandName[:idx]
+	commandName, rawArgs := splitFirstArg(content)
+	if commandName == "" {
+		return
 	}
 
-	r.execute(commandName, &Context{
+	ctx := &Context{
+		registry:    r,
 		client:      event.Client(),
 		messageData: event,
 		data:        r.data,
 		isSlash:     false,
-	}, false)
-}
+		prefix:      prefix,
+		rawArgs:     rawArgs,
+		response:    previous,
+		editing:     previous != nil,
+	}
+	r.execute(commandName, ctx, false)
 
-func (r *Registry) OnSlashCommand(event *events.ApplicationCommandInteractionCreate) {
-	commandName := event.Data.CommandName()
-	r.execute(commandName, &Context{
-		client:    event.Client(),
-		slashData: event,
-		data:      r.data,
-		isSlash:   true,
-	}, true)
+	if r.edits != nil {
+		ctx.responseMu.Lock()
+		response := ctx.response
+		ctx.responseMu.Unlock()
+
+		r.edits.track(event.MessageID, event.Message.Content, response)
+	}
 }
 
-func (r *Registry) execute(name string, ctx *Context, isSlash bool) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+// matchPrefix finds the prefix the message starts with, preferring the longest
+// one, and returns the content following it.
+func (r *Registry) matchPrefix(event *events.MessageCreate) (string, string, bool) {
+	content := event.Message.Content
 
-	for _, cmd := range r.commands {
-		if isSlash && !cmd.SlashCommand {
-			continue
+	if r.mentionPrefix {
+		selfID := event.Client().ID().String()
+		for _, mention := range []string{"<@" + selfID + ">", "<@!" + selfID + ">"} {
+			if strings.HasPrefix(content, mention) {
+				return mention + " ", content[len(mention):], true
+			}
 		}
+	}
 
-		if !isSlash && !cmd.PrefixCommand {
-			continue
-		}
+	prefixes := []string{r.prefix}
+	if r.prefixResolver != nil {
+		prefixes = r.prefixResolver(event)
+	}
 
-		if cmd.Name != name && !slices.Contains(cmd.Aliases, name) {
-			continue
+	var matched string
+	for _, prefix := range prefixes {
+		if prefix != "" && len(prefix) > len(matched) && strings.HasPrefix(content, prefix) {
+			matched = prefix
 		}
+	}
+	if matched == "" {
+		return "", "", false
+	}
+	return matched, content[len(matched):], true
+}
 
-		if err := cmd.Execute(ctx); err != nil {
-			r.onError(err, ctx)
-		}
+// OnSlashCommand handles slash commands as well as user and message context
+// menu commands.
+func (r *Registry) OnSlashCommand(event *events.ApplicationCommandInteractionCreate) {
+	ctx := &Context{
+		registry:    r,
+		client:      event.Client(),
+		slashData:   event,
+		interaction: event.ApplicationCommandInteraction,
+		respond:     event.Respond,
+		data:        r.data,
+		isSlash:     true,
+	}
+
+	commandType := event.Data.Type()
+	if commandType == discord.ApplicationCommandTypeSlash {
+		r.execute(event.Data.CommandName(), ctx, true)
 		return
 	}
+
+	cmd := r.findContextMenu(event.Data.CommandName(), commandType)
+	if cmd == nil {
+		return
+	}
+
+	ctx.path = []*Command{cmd}
+	if err := r.chain(r.run, ctx.path)(ctx); err != nil {
+		r.onError(err, ctx)
+	}
 }
 
-func (r *Registry) OnReady(event *events.Ready) {
-	if err := r.RegisterSlash(event.Client()); err != nil {
-		slog.Error("Failed to register slash commands", slog.Any("error", err))
+func (r *Registry) execute(name string, ctx *Context, isSlash bool) {
+	cmd := r.find(name, isSlash)
+	if cmd == nil {
+		if !isSlash {
+			r.suggest(name, ctx)
+		}
 		return
 	}
 
-	if r.onReady != nil {
-		r.onReady(event)
+	path, err := resolveSubCommands(cmd, ctx)
+	if err != nil {
+		r.onError(err, ctx)
+		return
+	}
+	ctx.path = path
+
+	if err := r.chain(r.run, path)(ctx); err != nil {
+		r.onError(err, ctx)
 	}
 }
 
-func (r *Registry) RegisterSlash(client bot.Client) error {
+func (r *Registry) run(ctx *Context) error {
+	if err := r.checkAvailability(ctx); err != nil {
+		return err
+	}
+	if err := r.runChecks(ctx, ctx.path); err != nil {
+		return err
+	}
+
+	cmd := ctx.Command()
+	if !ctx.isSlash {
+		options, err := parseArgs(ctx, ctx.rawArgs, cmd.AllOptions())
+		if err != nil {
+			return err
+		}
+		ctx.options = options
+	}
+
+	if err := r.checkCooldown(ctx); err != nil {
+		return err
+	}
+
+	if confirmed, err := r.confirmCommand(ctx); err != nil || !confirmed {
+		return err
+	}
+
+	return cmd.Execute(ctx)
+}
+
+func (r *Registry) find(name string, isSlash bool) *Command {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	slashCommands := make([]discord.ApplicationCommandCreate, 0, len(r.commands))
-	for _, cmd := range r.commands {
-		if !cmd.SlashCommand {
-			continue
-		}
+	if isSlash {
+		return r.index.slash[strings.ToLower(name)]
+	}
+	return r.index.prefix[strings.ToLower(name)]
+}
+
+func (r *Registry) findContextMenu(name string, commandType discord.ApplicationCommandType) *Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.index.contextMenu[contextMenuKey(commandType, name)]
+}
+
+func resolveSubCommands(cmd *Command, ctx *Context) ([]*Command, error) {
+	path := []*Command{cmd}
+
+	if ctx.isSlash {
+		data := ctx.slashData.SlashCommandInteractionData()
+		for _, name := range []*string{data.SubCommandGroupName, data.SubCommandName} {
+			if name == nil {
+				continue
+			}
 
-		slashCommands = append(slashCommands, discord.SlashCommandCreate{
-			Name:        cmd.Name,
-			Description: cmd.Description,
-			Options:     cmd.Options,
-		})
+			sub := cmd.SubCommand(*name)
+			if sub == nil {
+				return nil, fmt.Errorf("unknown subcommand %q for command %q", *name, cmd.Name)
+			}
+			cmd = sub
+			path = append(path, sub)
+		}
+	} else {
+		for len(cmd.SubCommands) > 0 {
+			name, rest := splitFirstArg(ctx.rawArgs)
+			sub := cmd.SubCommand(name)
+			if sub == nil {
+				break
+			}
+			cmd = sub
+			path = append(path, sub)
+			ctx.rawArgs = rest
+		}
 	}
 
-	if len(slashCommands) == 0 {
-		return nil
+	if cmd.Execute == nil {
+		return nil, &utils.UserError{Message: ctx.T("commands.needs_subcommand", commandPath(path), cmd.subCommandNames())}
 	}
 
-	_, err := client.Rest().SetGlobalCommands(client.ApplicationID(), slashCommands)
-	if err != nil {
-		return fmt.Errorf("failed to set global commands: %w", err)
+	return path, nil
+}
+
+func (r *Registry) OnReady(event *events.Ready) {
+	if err := r.RegisterSlash(event.Client()); err != nil {
+		slog.Error("Failed to register slash commands", slog.Any("error", err))
+		return
 	}
 
-	return nil
+	if r.onReady != nil {
+		r.onReady(event)
+	}
 }
 
 func (r *Registry) Commands() []*Command {
@@ -153,8 +403,42 @@ func (r *Registry) Commands() []*Command {
 	return r.commands
 }
 
+// Add registers the command. Names and aliases are matched case-insensitively
+// and must be unique, Add panics when one is already taken since that's a
+// programming error the bot shouldn't start with.
 func (r *Registry) Add(cmd *Command) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	if err := r.index.add(cmd); err != nil {
+		panic("registry: " + err.Error())
+	}
+
+	if cmd.Module == "" {
+		cmd.Module = r.loadingModule
+	}
 	r.commands = append(r.commands, cmd)
 }
+
+// LoadModule runs register and tags every command it adds with the module
+// name, which the help command uses for grouping.
+func (r *Registry) LoadModule(name string, register func(r *Registry)) {
+	r.mu.Lock()
+	r.loadingModule = name
+	r.modules = append(r.modules, name)
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.loadingModule = ""
+		r.mu.Unlock()
+	}()
+
+	register(r)
+}
+
+func (r *Registry) Modules() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.modules
+}
@@ -0,0 +1,267 @@
+package registry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/registry/registrytest"
+)
+
+// newEchoHarness registers an echo command that counts its runs.
+func newEchoHarness(opts registry.Options) (*registrytest.Harness, *int) {
+	runs := new(int)
+	h := registrytest.New(opts)
+	h.Registry.Add(&registry.Command{
+		Name:          "echo",
+		Description:   "Repeat the text.",
+		PrefixCommand: true,
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{Name: "text", Description: "Text to repeat", Required: true},
+		},
+		Execute: func(ctx *registry.Context) error {
+			*runs++
+			text, _ := ctx.GetStringOption("text")
+			return ctx.Say(text)
+		},
+	})
+	return h, runs
+}
+
+func TestMatchPrefix(t *testing.T) {
+	prefixes := func(*events.MessageCreate) []string { return []string{"!", "!!", "flexo "} }
+
+	tests := []struct {
+		name    string
+		opts    registry.Options
+		content string
+		prefix  string // empty when nothing should run
+	}{
+		{name: "prefix", opts: registry.Options{Prefix: "!"}, content: "!echo hi", prefix: "!"},
+		{name: "no prefix", opts: registry.Options{Prefix: "!"}, content: "echo hi"},
+		{name: "mention", opts: registry.Options{MentionPrefix: true}, content: "<@1> echo hi", prefix: "<@1> "},
+		{name: "nickname mention", opts: registry.Options{MentionPrefix: true}, content: "<@!1> echo hi", prefix: "<@!1> "},
+		{name: "mention without space", opts: registry.Options{MentionPrefix: true}, content: "<@1>echo hi", prefix: "<@1> "},
+		{name: "mention next to a prefix", opts: registry.Options{Prefix: "!", MentionPrefix: true}, content: "<@1> echo hi", prefix: "<@1> "},
+		{name: "mention of someone else", opts: registry.Options{MentionPrefix: true}, content: "<@2> echo hi"},
+		{name: "mention prefix disabled", opts: registry.Options{Prefix: "!"}, content: "<@1> echo hi"},
+		{name: "mention without command", opts: registry.Options{MentionPrefix: true}, content: "<@1>"},
+		{name: "mention with only spaces", opts: registry.Options{MentionPrefix: true}, content: "<@1>   "},
+		{name: "shorter of overlapping prefixes", opts: registry.Options{PrefixResolver: prefixes}, content: "!echo hi", prefix: "!"},
+		{name: "longer of overlapping prefixes", opts: registry.Options{PrefixResolver: prefixes}, content: "!!echo hi", prefix: "!!"},
+		{name: "prefix with a space", opts: registry.Options{PrefixResolver: prefixes}, content: "flexo echo hi", prefix: "flexo "},
+		{name: "resolver replaces prefix", opts: registry.Options{Prefix: "?", PrefixResolver: prefixes}, content: "?echo hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := registrytest.New(tt.opts)
+			var ran bool
+			h.Registry.Add(&registry.Command{
+				Name:          "echo",
+				PrefixCommand: true,
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{Name: "text", Description: "Text to repeat"},
+				},
+				Execute: func(ctx *registry.Context) error {
+					ran = true
+					if ctx.Prefix() != tt.prefix || ctx.RawArgs() != "hi" {
+						t.Errorf("got prefix %q and arguments %q, want %q and hi", ctx.Prefix(), ctx.RawArgs(), tt.prefix)
+					}
+					return nil
+				},
+			})
+
+			result := h.Prefix(tt.content)
+			if result.Err != nil || len(result.Responses) != 0 {
+				t.Errorf("got error %v and responses %+v, want neither", result.Err, result.Responses)
+			}
+			if want := tt.prefix != ""; ran != want {
+				t.Errorf("got ran %v, want %v", ran, want)
+			}
+		})
+	}
+}
+
+func TestEditTracking(t *testing.T) {
+	h, runs := newEchoHarness(registry.Options{Prefix: "!", EditTracking: time.Minute})
+
+	sent := h.Prefix("!echo never")
+	if got := sent.Last(); got.Content != "never" || got.Edit {
+		t.Fatalf("got %+v, want a new message saying never", got)
+	}
+
+	edited := h.Edit(sent.MessageID, "!echo gonna")
+	if got := edited.Last(); got.Content != "gonna" || !got.Edit {
+		t.Errorf("got %+v, want the response edited to gonna", got)
+	}
+
+	// Discord sends another update with the same content once it unfurls a
+	// link in the edited message.
+	if unfurled := h.Edit(sent.MessageID, "!echo gonna"); len(unfurled.Responses) != 0 {
+		t.Errorf("the same content ran again: %+v", unfurled.Responses)
+	}
+	if *runs != 2 {
+		t.Errorf("got %d runs, want 2", *runs)
+	}
+
+	again := h.Edit(sent.MessageID, "!echo give")
+	if got := again.Last(); got.Content != "give" || !got.Edit {
+		t.Errorf("got %+v, want the response edited to give", got)
+	}
+}
+
+func TestEditTrackingNewCommand(t *testing.T) {
+	h, runs := newEchoHarness(registry.Options{Prefix: "!", EditTracking: time.Minute})
+
+	sent := h.Prefix("echo never")
+	if len(sent.Responses) != 0 {
+		t.Fatalf("a message without prefix got %+v", sent.Responses)
+	}
+
+	edited := h.Edit(sent.MessageID, "!echo never")
+	if got := edited.Last(); got.Content != "never" || got.Edit {
+		t.Errorf("got %+v, want a new message saying never", got)
+	}
+	h.Edit(sent.MessageID, "!echo never")
+	if *runs != 1 {
+		t.Errorf("got %d runs, want 1", *runs)
+	}
+}
+
+func TestEditTrackingDisabled(t *testing.T) {
+	h, runs := newEchoHarness(registry.Options{Prefix: "!"})
+
+	sent := h.Prefix("!echo never")
+	if edited := h.Edit(sent.MessageID, "!echo gonna"); len(edited.Responses) != 0 {
+		t.Errorf("got %+v, want edits ignored", edited.Responses)
+	}
+	if *runs != 1 {
+		t.Errorf("got %d runs, want 1", *runs)
+	}
+}
+
+func TestSubCommands(t *testing.T) {
+	h := registrytest.New(registry.Options{Prefix: "!"})
+
+	var ran string
+	leaf := func(option string) func(ctx *registry.Context) error {
+		return func(ctx *registry.Context) error {
+			value, _ := ctx.GetStringOption(option)
+			ran = ctx.CommandPath() + ": " + value
+			return nil
+		}
+	}
+	h.Registry.Add(&registry.Command{
+		Name:          "policy",
+		Description:   "Restrict commands.",
+		PrefixCommand: true,
+		SlashCommand:  true,
+		SubCommands: []*registry.Command{
+			{
+				Name:        "allow",
+				Description: "Allow a command.",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{Name: "target", Description: "Command", Required: true},
+				},
+				Execute: leaf("target"),
+			},
+			{
+				Name:        "voice",
+				Description: "Manage voice channels.",
+				SubCommands: []*registry.Command{
+					{
+						Name:        "add",
+						Description: "Add a channel.",
+						Options: []discord.ApplicationCommandOption{
+							discord.ApplicationCommandOptionString{Name: "channel", Description: "Channel", Required: true},
+						},
+						Execute: leaf("channel"),
+					},
+					{
+						Name:        "remove",
+						Description: "Remove a channel.",
+						Aliases:     []string{"rm"},
+						Options: []discord.ApplicationCommandOption{
+							discord.ApplicationCommandOptionString{Name: "channel", Description: "Channel"},
+						},
+						Execute: leaf("channel"),
+					},
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		invoke func() *registrytest.Result
+		want   string
+		err    string
+	}{
+		{
+			name:   "prefix subcommand",
+			invoke: func() *registrytest.Result { return h.Prefix("!policy allow play music") },
+			want:   "policy allow: play music",
+		},
+		{
+			name:   "slash subcommand",
+			invoke: func() *registrytest.Result { return h.Slash("policy allow", map[string]any{"target": "play"}) },
+			want:   "policy allow: play",
+		},
+		{
+			name:   "prefix group",
+			invoke: func() *registrytest.Result { return h.Prefix("!policy voice add lobby") },
+			want:   "policy voice add: lobby",
+		},
+		{
+			name:   "slash group",
+			invoke: func() *registrytest.Result { return h.Slash("policy voice add", map[string]any{"channel": "lobby"}) },
+			want:   "policy voice add: lobby",
+		},
+		{
+			name:   "prefix alias in any case",
+			invoke: func() *registrytest.Result { return h.Prefix("!policy VOICE Rm lobby") },
+			want:   "policy voice remove: lobby",
+		},
+		{
+			name:   "slash optional left out",
+			invoke: func() *registrytest.Result { return h.Slash("policy voice remove", nil) },
+			want:   "policy voice remove: ",
+		},
+		{
+			name:   "prefix without subcommand",
+			invoke: func() *registrytest.Result { return h.Prefix("!policy") },
+			err:    "`policy` needs a subcommand: `allow`, `voice`.",
+		},
+		{
+			name:   "prefix group without subcommand",
+			invoke: func() *registrytest.Result { return h.Prefix("!policy voice lobby") },
+			err:    "`policy voice` needs a subcommand: `add`, `remove`.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = ""
+			result := tt.invoke()
+			if tt.err != "" {
+				if result.Err == nil || result.Err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", result.Err, tt.err)
+				}
+				if ran != "" {
+					t.Errorf("%s ran", ran)
+				}
+				return
+			}
+			if result.Err != nil {
+				t.Fatalf("unexpected error: %v", result.Err)
+			}
+			if ran != tt.want {
+				t.Errorf("got %q, want %q", ran, tt.want)
+			}
+		})
+	}
+}
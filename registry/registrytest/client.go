@@ -0,0 +1,135 @@
+package registrytest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// client implements the parts of bot.Client the registry uses. Calling
+// anything else panics, which Recover reports as the command's error.
+type client struct {
+	bot.Client
+	h    *Harness
+	rest *restClient
+}
+
+func newClient(h *Harness) *client {
+	return &client{h: h, rest: &restClient{h: h}}
+}
+
+func (c *client) Logger() *slog.Logger {
+	return slog.Default()
+}
+
+func (c *client) ApplicationID() snowflake.ID {
+	return c.h.Self.ID
+}
+
+func (c *client) ID() snowflake.ID {
+	return c.h.Self.ID
+}
+
+func (c *client) Caches() cache.Caches {
+	return c.h.Caches
+}
+
+func (c *client) Rest() rest.Rest {
+	return c.rest
+}
+
+func (c *client) UpdateVoiceState(_ context.Context, guildID snowflake.ID, channelID *snowflake.ID, _ bool, _ bool) error {
+	if channelID == nil {
+		c.h.Caches.RemoveVoiceState(guildID, c.h.Self.ID)
+		return nil
+	}
+	c.h.Caches.AddVoiceState(discord.VoiceState{GuildID: guildID, ChannelID: channelID, UserID: c.h.Self.ID})
+	return nil
+}
+
+// restClient records the messages commands send instead of calling Discord.
+type restClient struct {
+	rest.Rest
+	h *Harness
+}
+
+func (r *restClient) message(channelID snowflake.ID, content string) *discord.Message {
+	return &discord.Message{
+		ID:        r.h.nextID(),
+		ChannelID: channelID,
+		Author:    r.h.Self,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (r *restClient) SendTyping(snowflake.ID, ...rest.RequestOpt) error {
+	return nil
+}
+
+func (r *restClient) CreateMessage(channelID snowflake.ID, messageCreate discord.MessageCreate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	r.h.record(createResponse(messageCreate))
+	return r.message(channelID, messageCreate.Content), nil
+}
+
+func (r *restClient) UpdateMessage(channelID snowflake.ID, messageID snowflake.ID, messageUpdate discord.MessageUpdate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	r.h.record(updateResponse(Response{Edit: true}, messageUpdate))
+
+	message := r.message(channelID, "")
+	message.ID = messageID
+	return message, nil
+}
+
+func (r *restClient) DeleteMessage(snowflake.ID, snowflake.ID, ...rest.RequestOpt) error {
+	return nil
+}
+
+func (r *restClient) UpdateInteractionResponse(_ snowflake.ID, _ string, messageUpdate discord.MessageUpdate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	r.h.editOriginal(messageUpdate)
+	return r.message(r.h.ChannelID, ""), nil
+}
+
+func (r *restClient) DeleteInteractionResponse(snowflake.ID, string, ...rest.RequestOpt) error {
+	return nil
+}
+
+func (r *restClient) CreateFollowupMessage(_ snowflake.ID, _ string, messageCreate discord.MessageCreate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	r.h.record(createResponse(messageCreate))
+	return r.message(r.h.ChannelID, messageCreate.Content), nil
+}
+
+func (r *restClient) UpdateFollowupMessage(_ snowflake.ID, _ string, messageID snowflake.ID, messageUpdate discord.MessageUpdate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	r.h.record(updateResponse(Response{Edit: true}, messageUpdate))
+
+	message := r.message(r.h.ChannelID, "")
+	message.ID = messageID
+	return message, nil
+}
+
+func (r *restClient) GetUser(userID snowflake.ID, _ ...rest.RequestOpt) (*discord.User, error) {
+	r.h.mu.Lock()
+	defer r.h.mu.Unlock()
+
+	user, ok := r.h.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("unknown user %s", userID)
+	}
+	return &user, nil
+}
+
+// GetMember only knows the members in the cache, which is where the registry
+// looked before.
+func (r *restClient) GetMember(guildID snowflake.ID, userID snowflake.ID, _ ...rest.RequestOpt) (*discord.Member, error) {
+	member, ok := r.h.Caches.Member(guildID, userID)
+	if !ok {
+		return nil, fmt.Errorf("unknown member %s", userID)
+	}
+	return &member, nil
+}
@@ -0,0 +1,774 @@
+// Package registrytest runs registry commands without a Discord connection.
+//
+// A Harness feeds prefix messages and interactions built from a fake author,
+// guild and channel through the registry's event handlers, exactly like the
+// gateway would, and records what the commands send back:
+//
+//	h := registrytest.New(registry.Options{Prefix: "!", Data: data})
+//	h.Registry.LoadModule("Music", (&modules.MusicModule{}).Register)
+//	h.JoinVoice(h.Author.ID, voiceChannelID)
+//
+//	result := h.Slash("queue remove", map[string]any{"position": 2})
+//	if result.Err != nil || result.Last().Content == "" {
+//		t.Fatal(...)
+//	}
+package registrytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/registry"
+	"github.com/goland-express/flexo/utils"
+)
+
+// Response is a message a command sent or edited, or a modal it opened.
+type Response struct {
+	Content    string
+	Embeds     []discord.Embed
+	Components []discord.ContainerComponent
+	Ephemeral  bool
+
+	// Edit is set when the response replaced an earlier one instead of
+	// sending a new message.
+	Edit bool
+
+	Modal *discord.ModalCreate
+}
+
+// Result is the outcome of one invocation.
+type Result struct {
+	Responses []Response
+
+	// Err is the error handed to OnError, if any. User errors are still
+	// presented, so their message shows up in Responses too.
+	Err error
+
+	// MessageID is the ID of the message Prefix sent, to pass to Edit.
+	MessageID snowflake.ID
+}
+
+// Last returns the most recent response, or a zero Response when the command
+// didn't send anything.
+func (r *Result) Last() Response {
+	if len(r.Responses) == 0 {
+		return Response{}
+	}
+	return r.Responses[len(r.Responses)-1]
+}
+
+// Harness holds the registry under test and the fake world commands run in.
+// The invocation fields can be changed between runs.
+type Harness struct {
+	Registry *registry.Registry
+	Caches   cache.Caches
+
+	// Self is the bot user, its ID doubles as the application ID.
+	Self discord.User
+
+	Author    discord.User
+	GuildID   *snowflake.ID
+	ChannelID snowflake.ID
+	Roles     []snowflake.ID
+	Locale    discord.Locale
+
+	// Permissions are the author's permissions, all of them by default.
+	// Interactions carry them, prefix commands see them through a role of the
+	// author.
+	Permissions discord.Permissions
+
+	// BotPermissions are the bot's permissions, all of them by default.
+	// Interactions carry them, prefix commands see them through a role of the
+	// bot.
+	BotPermissions discord.Permissions
+
+	// NSFW marks the current channel as age-restricted.
+	NSFW bool
+
+	// ThreadParentID makes the current channel a thread in that text channel
+	// when set. NSFW then applies to the parent.
+	ThreadParentID snowflake.ID
+
+	// Answer presses the button of confirmation prompts, see
+	// registry.Context.Confirm, as soon as they are posted: "yes" or "no".
+	// Prompts are left to time out when it's empty.
+	Answer string
+
+	client *client
+
+	mu        sync.Mutex
+	lastID    snowflake.ID
+	users     map[snowflake.ID]discord.User
+	messages  map[snowflake.ID]discord.Message
+	responses []Response
+	errs      []error
+	deferred  *Response
+	clicked   *Response
+}
+
+// New creates a registry from opts and a harness around it. OnError still
+// runs, defaulting to registry.DefaultErrorFunc, after the error is recorded.
+func New(opts registry.Options) *Harness {
+	guildID := snowflake.ID(100)
+
+	h := &Harness{
+		Caches:         cache.New(cache.WithCaches(registry.CacheFlags)),
+		Self:           discord.User{ID: 1, Username: "flexo", Bot: true},
+		Author:         discord.User{ID: 2, Username: "tester"},
+		GuildID:        &guildID,
+		ChannelID:      200,
+		Locale:         discord.LocaleEnglishUS,
+		Permissions:    discord.PermissionsAll,
+		BotPermissions: discord.PermissionsAll,
+		lastID:         1000,
+		users:          make(map[snowflake.ID]discord.User),
+		messages:       make(map[snowflake.ID]discord.Message),
+	}
+	h.client = newClient(h)
+
+	onError := opts.OnError
+	if onError == nil {
+		onError = registry.DefaultErrorFunc
+	}
+	opts.OnError = func(err error, ctx *registry.Context) {
+		h.mu.Lock()
+		h.errs = append(h.errs, err)
+		h.mu.Unlock()
+
+		onError(err, ctx)
+	}
+
+	h.Registry = registry.New(opts)
+	return h
+}
+
+// AddUser makes the user resolvable, for example as a mentioned argument.
+func (h *Harness) AddUser(user discord.User) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.users[user.ID] = user
+}
+
+// JoinVoice puts the user in a voice channel of the current guild, pass the
+// bot's ID to have it connected somewhere. The channel is cached without
+// overwrites when it isn't yet.
+func (h *Harness) JoinVoice(userID, channelID snowflake.ID) {
+	if _, ok := h.Caches.Channel(channelID); !ok {
+		h.cacheChannel(channelID, discord.ChannelTypeGuildVoice, "voice", false)
+	}
+	h.Caches.AddVoiceState(discord.VoiceState{GuildID: *h.GuildID, ChannelID: &channelID, UserID: userID})
+}
+
+// LeaveVoice disconnects the user from the current guild's voice channels.
+func (h *Harness) LeaveVoice(userID snowflake.ID) {
+	h.Caches.RemoveVoiceState(*h.GuildID, userID)
+}
+
+// Prefix sends a message with the given content, which has to include the
+// prefix.
+func (h *Harness) Prefix(content string) *Result {
+	h.begin()
+
+	message := discord.Message{
+		ID:        h.nextID(),
+		Content:   content,
+		ChannelID: h.ChannelID,
+		GuildID:   h.GuildID,
+		Author:    h.Author,
+		CreatedAt: time.Now(),
+		Mentions:  h.mentions(content),
+	}
+	if h.GuildID != nil {
+		roleIDs := append(slices.Clone(h.Roles), authorRoleID)
+		message.Member = &discord.Member{GuildID: *h.GuildID, User: h.Author, RoleIDs: roleIDs, JoinedAt: time.Now()}
+	}
+
+	h.mu.Lock()
+	h.messages[message.ID] = message
+	h.mu.Unlock()
+
+	h.Registry.OnMessage(&events.MessageCreate{GenericMessage: h.messageEvent(message)})
+	result := h.end()
+	result.MessageID = message.ID
+	return result
+}
+
+// Edit changes the content of a message sent with Prefix, like the author
+// editing it. Editing it to the same content is what Discord does when it
+// unfurls a link in an edited message. The message cache is off, so the
+// update doesn't carry the old message.
+func (h *Harness) Edit(messageID snowflake.ID, content string) *Result {
+	h.begin()
+
+	mentions := h.mentions(content)
+
+	h.mu.Lock()
+	message, ok := h.messages[messageID]
+	if ok {
+		message.Content = content
+		message.EditedTimestamp = utils.Ptr(time.Now())
+		message.Mentions = mentions
+		h.messages[messageID] = message
+	}
+	h.mu.Unlock()
+	if !ok {
+		panic(fmt.Sprintf("registrytest: no message %s to edit", messageID))
+	}
+
+	h.Registry.OnMessageUpdate(&events.MessageUpdate{GenericMessage: h.messageEvent(message)})
+	result := h.end()
+	result.MessageID = messageID
+	return result
+}
+
+func (h *Harness) messageEvent(message discord.Message) *events.GenericMessage {
+	return &events.GenericMessage{
+		GenericEvent: events.NewGenericEvent(h.client, 0, 0),
+		MessageID:    message.ID,
+		Message:      message,
+		ChannelID:    message.ChannelID,
+		GuildID:      message.GuildID,
+	}
+}
+
+// Role IDs of the roles that hold the author's and the bot's permissions.
+const (
+	authorRoleID snowflake.ID = 101
+	botRoleID    snowflake.ID = 102
+)
+
+// cacheGuild puts what permissions are resolved from in the cache:
+// the roles, the bot's member and the current channel, or thread and parent.
+func (h *Harness) cacheGuild() {
+	guildID := *h.GuildID
+	h.Caches.AddRole(discord.Role{ID: guildID, GuildID: guildID, Name: "@everyone"})
+	h.Caches.AddRole(discord.Role{ID: authorRoleID, GuildID: guildID, Name: "author", Permissions: h.Permissions})
+	h.Caches.AddRole(discord.Role{ID: botRoleID, GuildID: guildID, Name: "bot", Permissions: h.BotPermissions})
+	h.Caches.AddMember(discord.Member{GuildID: guildID, User: h.Self, RoleIDs: []snowflake.ID{botRoleID}, JoinedAt: time.Now()})
+
+	if h.ThreadParentID == 0 {
+		h.cacheChannel(h.ChannelID, discord.ChannelTypeGuildText, "general", h.NSFW)
+		return
+	}
+	h.cacheChannel(h.ThreadParentID, discord.ChannelTypeGuildText, "general", h.NSFW)
+	h.cacheThread(h.ChannelID, h.ThreadParentID, "thread")
+}
+
+func (h *Harness) cacheChannel(id snowflake.ID, channelType discord.ChannelType, name string, nsfw bool) {
+	var channel discord.UnmarshalChannel
+	err := remarshal(map[string]any{
+		"id":       id,
+		"type":     channelType,
+		"guild_id": *h.GuildID,
+		"name":     name,
+		"nsfw":     nsfw,
+	}, &channel)
+	if err != nil {
+		h.recordErr(fmt.Errorf("failed to build channel: %w", err))
+		return
+	}
+	h.Caches.AddChannel(channel.Channel.(discord.GuildChannel))
+}
+
+func (h *Harness) cacheThread(id, parentID snowflake.ID, name string) {
+	var thread discord.GuildThread
+	err := remarshal(map[string]any{
+		"id":        id,
+		"type":      discord.ChannelTypeGuildPublicThread,
+		"guild_id":  *h.GuildID,
+		"parent_id": parentID,
+		"owner_id":  h.Author.ID,
+		"name":      name,
+	}, &thread)
+	if err != nil {
+		h.recordErr(fmt.Errorf("failed to build thread: %w", err))
+		return
+	}
+	h.Caches.AddChannel(thread)
+}
+
+// Slash runs a slash command. The command is given by its path, like "queue
+// remove", and options are passed by name: strings, numbers and bools as
+// themselves, users as discord.User or their ID and channels, roles and
+// mentionables as snowflake.ID.
+func (h *Harness) Slash(command string, options map[string]any) *Result {
+	h.begin()
+
+	data, err := h.slashData(strings.Fields(command), options)
+	if err != nil {
+		return &Result{Err: err}
+	}
+
+	h.dispatchCommand(data)
+	return h.end()
+}
+
+// UserCommand runs a user context menu command on the target.
+func (h *Harness) UserCommand(name string, target discord.User) *Result {
+	h.begin()
+
+	h.dispatchCommand(map[string]any{
+		"id":        h.nextID(),
+		"name":      name,
+		"type":      discord.ApplicationCommandTypeUser,
+		"target_id": target.ID,
+		"resolved": map[string]any{
+			"users": map[snowflake.ID]discord.User{target.ID: target},
+		},
+	})
+	return h.end()
+}
+
+// MessageCommand runs a message context menu command on the target.
+func (h *Harness) MessageCommand(name string, target discord.Message) *Result {
+	h.begin()
+
+	if target.ID == 0 {
+		target.ID = h.nextID()
+	}
+	if target.ChannelID == 0 {
+		target.ChannelID = h.ChannelID
+	}
+
+	h.dispatchCommand(map[string]any{
+		"id":        h.nextID(),
+		"name":      name,
+		"type":      discord.ApplicationCommandTypeMessage,
+		"target_id": target.ID,
+		"resolved": map[string]any{
+			"messages": map[snowflake.ID]discord.Message{target.ID: target},
+		},
+	})
+	return h.end()
+}
+
+// Click presses the button with the given custom ID on a message the bot sent
+// earlier, like one of a previous Result's responses.
+func (h *Harness) Click(message Response, customID string) *Result {
+	h.begin()
+	if err := h.press(message, customID); err != nil {
+		return &Result{Err: err}
+	}
+	return h.end()
+}
+
+// press dispatches the click of a button as part of the current invocation.
+func (h *Harness) press(message Response, customID string) error {
+	h.mu.Lock()
+	h.clicked = &message
+	h.mu.Unlock()
+
+	payload := h.interaction(discord.InteractionTypeComponent, map[string]any{
+		"custom_id":      customID,
+		"component_type": discord.ComponentTypeButton,
+	})
+	payload["message"] = map[string]any{
+		"id":         h.nextID(),
+		"channel_id": h.ChannelID,
+		"author":     h.Self,
+		"content":    message.Content,
+		"embeds":     message.Embeds,
+		"components": message.Components,
+		"timestamp":  time.Now(),
+	}
+
+	var interaction discord.ComponentInteraction
+	if err := remarshal(payload, &interaction); err != nil {
+		return fmt.Errorf("failed to build interaction: %w", err)
+	}
+
+	h.Registry.OnComponent(&events.ComponentInteractionCreate{
+		GenericEvent:         events.NewGenericEvent(h.client, 0, 0),
+		ComponentInteraction: interaction,
+		Respond:              h.respond,
+	})
+	return nil
+}
+
+// Submit submits the modal with the given custom ID, usually taken from a
+// Response.Modal, with values by text input custom ID.
+func (h *Harness) Submit(customID string, values map[string]string) *Result {
+	h.begin()
+
+	rows := make([]map[string]any, 0, len(values))
+	for id, value := range values {
+		rows = append(rows, map[string]any{
+			"type":       discord.ComponentTypeActionRow,
+			"components": []map[string]any{{"type": discord.ComponentTypeTextInput, "custom_id": id, "value": value}},
+		})
+	}
+
+	payload := h.interaction(discord.InteractionTypeModalSubmit, map[string]any{
+		"custom_id":  customID,
+		"components": rows,
+	})
+
+	var interaction discord.ModalSubmitInteraction
+	if err := remarshal(payload, &interaction); err != nil {
+		return &Result{Err: fmt.Errorf("failed to build interaction: %w", err)}
+	}
+
+	h.Registry.OnModalSubmit(&events.ModalSubmitInteractionCreate{
+		GenericEvent:           events.NewGenericEvent(h.client, 0, 0),
+		ModalSubmitInteraction: interaction,
+		Respond:                h.respond,
+	})
+	return h.end()
+}
+
+// dispatchCommand wraps the interaction data the way Discord sends it and
+// hands it to the registry.
+func (h *Harness) dispatchCommand(data map[string]any) {
+	var interaction discord.ApplicationCommandInteraction
+	if err := remarshal(h.interaction(discord.InteractionTypeApplicationCommand, data), &interaction); err != nil {
+		h.recordErr(fmt.Errorf("failed to build interaction: %w", err))
+		return
+	}
+
+	h.Registry.OnSlashCommand(&events.ApplicationCommandInteractionCreate{
+		GenericEvent:                  events.NewGenericEvent(h.client, 0, 0),
+		ApplicationCommandInteraction: interaction,
+		Respond:                       h.respond,
+	})
+}
+
+// interaction builds the payload of an interaction by the current author.
+func (h *Harness) interaction(interactionType discord.InteractionType, data map[string]any) map[string]any {
+	channel := map[string]any{"id": h.ChannelID, "type": discord.ChannelTypeDM, "permissions": h.Permissions, "nsfw": h.NSFW}
+	payload := map[string]any{
+		"id":                             h.nextID(),
+		"application_id":                 h.Self.ID,
+		"type":                           interactionType,
+		"token":                          "registrytest",
+		"version":                        1,
+		"locale":                         h.Locale,
+		"channel":                        channel,
+		"entitlements":                   []any{},
+		"authorizing_integration_owners": map[string]any{},
+		"data":                           data,
+	}
+
+	if h.GuildID != nil {
+		channel["type"] = discord.ChannelTypeGuildText
+		channel["guild_id"] = *h.GuildID
+		if h.ThreadParentID != 0 {
+			channel["type"] = discord.ChannelTypeGuildPublicThread
+			channel["parent_id"] = h.ThreadParentID
+		}
+		payload["guild_id"] = *h.GuildID
+		payload["app_permissions"] = h.BotPermissions
+		payload["member"] = map[string]any{
+			"user":        h.Author,
+			"roles":       h.Roles,
+			"joined_at":   time.Now(),
+			"permissions": h.Permissions,
+		}
+	} else {
+		payload["user"] = h.Author
+	}
+	return payload
+}
+
+func (h *Harness) slashData(names []string, values map[string]any) (map[string]any, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no command given")
+	}
+
+	cmd := h.Registry.Get(names[0])
+	if cmd == nil || !cmd.SlashCommand {
+		return nil, fmt.Errorf("unknown slash command %q", names[0])
+	}
+
+	path := []*registry.Command{cmd}
+	for _, name := range names[1:] {
+		sub := path[len(path)-1].SubCommand(name)
+		if sub == nil {
+			return nil, fmt.Errorf("unknown subcommand %q", name)
+		}
+		path = append(path, sub)
+	}
+
+	leaf := path[len(path)-1]
+	resolvedUsers := make(map[snowflake.ID]discord.User)
+	options := make([]map[string]any, 0, len(values))
+	for _, option := range leaf.AllOptions() {
+		value, ok := values[option.OptionName()]
+		if !ok {
+			continue
+		}
+
+		encoded, err := h.encodeOption(option, value, resolvedUsers)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, map[string]any{
+			"name":  option.OptionName(),
+			"type":  option.Type(),
+			"value": encoded,
+		})
+	}
+	if len(options) != len(values) {
+		return nil, fmt.Errorf("command %q doesn't have some of the given options", commandPath(path))
+	}
+
+	// Nest the options under the subcommand and group they belong to.
+	var nested any = options
+	for i := len(path) - 1; i > 0; i-- {
+		optionType := discord.ApplicationCommandOptionTypeSubCommand
+		if i < len(path)-1 {
+			optionType = discord.ApplicationCommandOptionTypeSubCommandGroup
+		}
+		nested = []map[string]any{{
+			"name":    path[i].Name,
+			"type":    optionType,
+			"options": nested,
+		}}
+	}
+
+	return map[string]any{
+		"id":       h.nextID(),
+		"name":     cmd.Name,
+		"type":     discord.ApplicationCommandTypeSlash,
+		"options":  nested,
+		"resolved": map[string]any{"users": resolvedUsers},
+	}, nil
+}
+
+func (h *Harness) encodeOption(option discord.ApplicationCommandOption, value any, resolvedUsers map[snowflake.ID]discord.User) (any, error) {
+	switch option.Type() {
+	case discord.ApplicationCommandOptionTypeUser:
+		switch v := value.(type) {
+		case discord.User:
+			resolvedUsers[v.ID] = v
+			return v.ID, nil
+		case snowflake.ID:
+			h.mu.Lock()
+			user, ok := h.users[v]
+			h.mu.Unlock()
+			if !ok {
+				user = discord.User{ID: v, Username: v.String()}
+			}
+			resolvedUsers[v] = user
+			return v, nil
+		}
+
+	case discord.ApplicationCommandOptionTypeChannel, discord.ApplicationCommandOptionTypeRole, discord.ApplicationCommandOptionTypeMentionable:
+		if id, ok := value.(snowflake.ID); ok {
+			return id, nil
+		}
+
+	case discord.ApplicationCommandOptionTypeAttachment:
+		return nil, fmt.Errorf("option %q: attachments aren't supported", option.OptionName())
+
+	default:
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("option %q: unsupported value %T", option.OptionName(), value)
+}
+
+// mentions resolves the user mentions in content against the added users.
+func (h *Harness) mentions(content string) []discord.User {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var mentions []discord.User
+	for id, user := range h.users {
+		if strings.Contains(content, "<@"+id.String()+">") || strings.Contains(content, "<@!"+id.String()+">") {
+			mentions = append(mentions, user)
+		}
+	}
+	return mentions
+}
+
+// respond is the interaction responder, it records the initial response.
+func (h *Harness) respond(responseType discord.InteractionResponseType, data discord.InteractionResponseData, _ ...rest.RequestOpt) error {
+	switch responseType {
+	case discord.InteractionResponseTypeCreateMessage:
+		h.record(createResponse(data.(discord.MessageCreate)))
+
+	case discord.InteractionResponseTypeDeferredCreateMessage:
+		deferred := Response{}
+		if message, ok := data.(discord.MessageCreate); ok {
+			deferred.Ephemeral = message.Flags.Has(discord.MessageFlagEphemeral)
+		}
+		h.mu.Lock()
+		h.deferred = &deferred
+		h.mu.Unlock()
+
+	case discord.InteractionResponseTypeModal:
+		modal := data.(discord.ModalCreate)
+		h.record(Response{Modal: &modal})
+
+	case discord.InteractionResponseTypeUpdateMessage:
+		if update, ok := data.(discord.MessageUpdate); ok {
+			h.mu.Lock()
+			previous := Response{Edit: true}
+			if h.clicked != nil {
+				previous = *h.clicked
+				previous.Edit = true
+			}
+			h.mu.Unlock()
+			h.record(updateResponse(previous, update))
+		}
+	}
+	return nil
+}
+
+// editOriginal records an edit of the original interaction response, which
+// completes a deferred one.
+func (h *Harness) editOriginal(update discord.MessageUpdate) {
+	h.mu.Lock()
+	var previous Response
+	if h.deferred != nil {
+		previous = *h.deferred
+		h.deferred = nil
+	} else {
+		for _, response := range h.responses {
+			if response.Modal == nil {
+				previous = response
+				previous.Edit = true
+				break
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	h.record(updateResponse(previous, update))
+}
+
+func createResponse(message discord.MessageCreate) Response {
+	return Response{
+		Content:    message.Content,
+		Embeds:     message.Embeds,
+		Components: message.Components,
+		Ephemeral:  message.Flags.Has(discord.MessageFlagEphemeral),
+	}
+}
+
+func updateResponse(previous Response, update discord.MessageUpdate) Response {
+	response := previous
+	if update.Content != nil {
+		response.Content = *update.Content
+	}
+	if update.Embeds != nil {
+		response.Embeds = *update.Embeds
+	}
+	if update.Components != nil {
+		response.Components = *update.Components
+	}
+	if update.Flags != nil {
+		response.Ephemeral = update.Flags.Has(discord.MessageFlagEphemeral)
+	}
+	return response
+}
+
+// record keeps a response and answers it when it's a confirmation prompt.
+// The prompt's command is waiting for the answer, which is handled like an
+// interaction arriving in the meantime.
+func (h *Harness) record(response Response) {
+	h.mu.Lock()
+	h.responses = append(h.responses, response)
+	answer := h.Answer
+	h.mu.Unlock()
+
+	if customID, ok := confirmButton(response, answer); ok {
+		if err := h.press(response, customID); err != nil {
+			h.recordErr(err)
+		}
+	}
+}
+
+// confirmButton finds the button of a confirmation prompt that gives the
+// answer, their custom IDs are "confirm:<invocation>:<answer>".
+func confirmButton(response Response, answer string) (string, bool) {
+	if answer == "" {
+		return "", false
+	}
+	for _, button := range Buttons(response) {
+		if strings.HasPrefix(button.CustomID, "confirm:") && strings.HasSuffix(button.CustomID, ":"+answer) {
+			return button.CustomID, true
+		}
+	}
+	return "", false
+}
+
+// Buttons returns the buttons of the response's action rows in order.
+func Buttons(response Response) []discord.ButtonComponent {
+	var buttons []discord.ButtonComponent
+	for _, container := range response.Components {
+		row, ok := container.(discord.ActionRowComponent)
+		if !ok {
+			continue
+		}
+		for _, component := range row {
+			if button, ok := component.(discord.ButtonComponent); ok {
+				buttons = append(buttons, button)
+			}
+		}
+	}
+	return buttons
+}
+
+func (h *Harness) recordErr(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errs = append(h.errs, err)
+}
+
+// begin resets what the previous invocation recorded and caches the guild for
+// the next one.
+func (h *Harness) begin() {
+	h.mu.Lock()
+	h.responses = nil
+	h.errs = nil
+	h.deferred = nil
+	h.clicked = nil
+	h.mu.Unlock()
+
+	if h.GuildID != nil {
+		h.cacheGuild()
+	}
+}
+
+func (h *Harness) end() *Result {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &Result{Responses: h.responses, Err: errors.Join(h.errs...)}
+}
+
+func (h *Harness) nextID() snowflake.ID {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID++
+	return h.lastID
+}
+
+func commandPath(path []*registry.Command) string {
+	names := make([]string, len(path))
+	for i, cmd := range path {
+		names[i] = cmd.Name
+	}
+	return strings.Join(names, " ")
+}
+
+// remarshal converts v into out through JSON, the only way to build
+// interactions since their fields are unexported.
+func remarshal(v any, out any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,278 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+var (
+	ErrAlreadyResponded = errors.New("interaction has already been responded to")
+	ErrNoResponse       = errors.New("there is no response to change")
+)
+
+// DefaultAllowedMentions only lets responses ping the users they mention,
+// never roles, @everyone or the author of the replied message.
+var DefaultAllowedMentions = discord.AllowedMentions{
+	Parse: []discord.AllowedMentionType{discord.AllowedMentionTypeUsers},
+}
+
+// Response describes a message sent by a command.
+type Response struct {
+	Content    string
+	Embeds     []discord.Embed
+	Components []discord.ContainerComponent
+	Files      []*discord.File
+
+	// Ephemeral makes the message only visible to the invoker. It is ignored
+	// by prefix commands and by the message replacing a deferred response,
+	// whose visibility is decided when deferring.
+	Ephemeral bool
+
+	// AllowedMentions defaults to DefaultAllowedMentions.
+	AllowedMentions *discord.AllowedMentions
+
+	// Silent suppresses push and desktop notifications.
+	Silent bool
+
+	// Reply references the invoking message for prefix commands.
+	Reply bool
+}
+
+// Respond sends the response using whatever call fits the current state, see
+// FollowUp.
+func (c *Context) Respond(response Response) error {
+	return c.FollowUp(c.messageCreate(response))
+}
+
+func (c *Context) messageCreate(response Response) discord.MessageCreate {
+	allowedMentions := response.AllowedMentions
+	if allowedMentions == nil {
+		allowedMentions = &DefaultAllowedMentions
+	}
+
+	message := discord.MessageCreate{
+		Content:         response.Content,
+		Embeds:          response.Embeds,
+		Components:      response.Components,
+		Files:           response.Files,
+		AllowedMentions: allowedMentions,
+	}
+
+	if response.Ephemeral && c.respond != nil {
+		message.Flags = message.Flags.Add(discord.MessageFlagEphemeral)
+	}
+	if response.Silent {
+		message.Flags = message.Flags.Add(discord.MessageFlagSuppressNotifications)
+	}
+	if response.Reply && c.messageData != nil {
+		message.MessageReference = &discord.MessageReference{
+			MessageID: &c.messageData.Message.ID,
+		}
+	}
+
+	return message
+}
+
+// Responded reports whether the interaction has been acknowledged, or for
+// prefix commands whether a reply was sent.
+func (c *Context) Responded() bool {
+	c.responseMu.Lock()
+	defer c.responseMu.Unlock()
+
+	if c.respond != nil {
+		return c.responded
+	}
+	return c.response != nil && !c.editing
+}
+
+// Defer acknowledges the interaction so the command has up to 15 minutes to
+// answer instead of 3 seconds, the next message sent replaces the loading
+// state. Prefix commands show a typing indicator instead. Deferring after
+// responding does nothing.
+func (c *Context) Defer(ephemeral bool) error {
+	if c.respond == nil {
+		if err := c.client.Rest().SendTyping(c.ChannelID()); err != nil {
+			return fmt.Errorf("failed to send typing indicator: %w", err)
+		}
+		return nil
+	}
+
+	var data discord.InteractionResponseData
+	if ephemeral {
+		data = discord.MessageCreate{Flags: discord.MessageFlagEphemeral}
+	}
+
+	err := c.initialResponse(discord.InteractionResponseTypeDeferredCreateMessage, data)
+	if errors.Is(err, ErrAlreadyResponded) {
+		return nil
+	}
+	return err
+}
+
+// EditResponse edits the first response, which for prefix commands is the
+// first message the command sent.
+func (c *Context) EditResponse(update discord.MessageUpdate) error {
+	c.responseMu.Lock()
+	defer c.responseMu.Unlock()
+
+	if c.respond != nil {
+		if !c.responded {
+			return ErrNoResponse
+		}
+
+		if _, err := c.client.Rest().UpdateInteractionResponse(c.interaction.ApplicationID(), c.interaction.Token(), update); err != nil {
+			return fmt.Errorf("failed to edit interaction response: %w", err)
+		}
+		c.deferred = false
+		return nil
+	}
+
+	if c.response == nil {
+		return ErrNoResponse
+	}
+
+	message, err := c.client.Rest().UpdateMessage(c.response.ChannelID, c.response.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to edit response message: %w", err)
+	}
+	c.response = message
+	return nil
+}
+
+// FollowUp sends a message using whatever call fits the current state: the
+// initial response, the edit of a deferred response or a follow-up message.
+func (c *Context) FollowUp(message discord.MessageCreate) error {
+	_, err := c.send(message)
+	return err
+}
+
+// send is FollowUp, returning a function that edits the sent message later
+// on, even once the context moved on to other messages.
+func (c *Context) send(message discord.MessageCreate) (func(discord.MessageUpdate) error, error) {
+	c.responseMu.Lock()
+	defer c.responseMu.Unlock()
+
+	client := c.client
+	if c.respond == nil {
+		editMessage := func(sent *discord.Message) func(discord.MessageUpdate) error {
+			return func(update discord.MessageUpdate) error {
+				_, err := client.Rest().UpdateMessage(sent.ChannelID, sent.ID, update)
+				return err
+			}
+		}
+
+		// A command re-run after its invocation was edited replaces its
+		// previous response, unless that was deleted in the meantime.
+		if c.editing {
+			c.editing = false
+			if edited, err := client.Rest().UpdateMessage(c.response.ChannelID, c.response.ID, messageUpdate(message)); err == nil {
+				c.response = edited
+				return editMessage(edited), nil
+			}
+			c.response = nil
+		}
+
+		created, err := client.Rest().CreateMessage(c.ChannelID(), message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create message: %w", err)
+		}
+		if c.response == nil {
+			c.response = created
+		}
+		return editMessage(created), nil
+	}
+
+	applicationID, token := c.interaction.ApplicationID(), c.interaction.Token()
+	editOriginal := func(update discord.MessageUpdate) error {
+		_, err := client.Rest().UpdateInteractionResponse(applicationID, token, update)
+		return err
+	}
+
+	switch {
+	case !c.responded:
+		if err := c.respond(discord.InteractionResponseTypeCreateMessage, message); err != nil {
+			return nil, fmt.Errorf("failed to create interaction response: %w", err)
+		}
+		c.responded = true
+		return editOriginal, nil
+
+	case c.deferred:
+		if err := editOriginal(messageUpdate(message)); err != nil {
+			return nil, fmt.Errorf("failed to edit deferred response: %w", err)
+		}
+		c.deferred = false
+		return editOriginal, nil
+
+	default:
+		created, err := client.Rest().CreateFollowupMessage(applicationID, token, message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create follow-up message: %w", err)
+		}
+		return func(update discord.MessageUpdate) error {
+			_, err := client.Rest().UpdateFollowupMessage(applicationID, token, created.ID, update)
+			return err
+		}, nil
+	}
+}
+
+// DeleteResponse deletes the first response.
+func (c *Context) DeleteResponse() error {
+	c.responseMu.Lock()
+	defer c.responseMu.Unlock()
+
+	if c.respond != nil {
+		if !c.responded {
+			return ErrNoResponse
+		}
+		if err := c.client.Rest().DeleteInteractionResponse(c.interaction.ApplicationID(), c.interaction.Token()); err != nil {
+			return fmt.Errorf("failed to delete interaction response: %w", err)
+		}
+		return nil
+	}
+
+	if c.response == nil {
+		return ErrNoResponse
+	}
+	if err := c.client.Rest().DeleteMessage(c.response.ChannelID, c.response.ID); err != nil {
+		return fmt.Errorf("failed to delete response message: %w", err)
+	}
+	c.response = nil
+	return nil
+}
+
+// initialResponse sends a response that is only valid as the first answer to
+// an interaction, such as deferring, opening a modal or updating the message
+// of a component.
+func (c *Context) initialResponse(responseType discord.InteractionResponseType, data discord.InteractionResponseData) error {
+	c.responseMu.Lock()
+	defer c.responseMu.Unlock()
+
+	if c.responded {
+		return ErrAlreadyResponded
+	}
+	if err := c.respond(responseType, data); err != nil {
+		return err
+	}
+
+	c.responded = true
+	c.deferred = responseType == discord.InteractionResponseTypeDeferredCreateMessage
+	return nil
+}
+
+// messageUpdate turns a message into an edit that replaces every part of the
+// message it is applied to.
+func messageUpdate(message discord.MessageCreate) discord.MessageUpdate {
+	embeds := append([]discord.Embed{}, message.Embeds...)
+	components := append([]discord.ContainerComponent{}, message.Components...)
+
+	return discord.MessageUpdate{
+		Content:         &message.Content,
+		Embeds:          &embeds,
+		Components:      &components,
+		Attachments:     &[]discord.AttachmentUpdate{},
+		Files:           message.Files,
+		AllowedMentions: message.AllowedMentions,
+	}
+}
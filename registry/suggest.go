@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	maxSuggestions      = 3
+	suggestionsCooldown = 30 * time.Second
+)
+
+// suggest replies to an unknown prefix command with the closest command names,
+// at most once per channel every suggestionsCooldown.
+func (r *Registry) suggest(name string, ctx *Context) {
+	if r.suggestCommands == nil || !r.suggestCommands(ctx.GuildID()) {
+		return
+	}
+
+	suggestions := r.closestCommands(name)
+	if len(suggestions) == 0 {
+		return
+	}
+
+	key := "suggestions:" + ctx.ChannelID().String()
+	if wait := r.cooldowns.take(key, &Cooldown{Per: suggestionsCooldown}, r.cooldowns.now()); wait > 0 {
+		return
+	}
+
+	for i, suggestion := range suggestions {
+		suggestions[i] = "`" + ctx.Prefix() + suggestion + "`"
+	}
+
+	err := ctx.Respond(Response{
+		Content: ctx.T("suggestions.did_you_mean", name, strings.Join(suggestions, ctx.T("suggestions.separator"))),
+		Reply:   true,
+	})
+	if err != nil {
+		slog.Warn("Failed to send command suggestions", slog.Any("error", err))
+	}
+}
+
+// closestCommands returns the names of the visible prefix commands whose name
+// or aliases are within a small edit distance of name, closest first.
+func (r *Registry) closestCommands(name string) []string {
+	name = strings.ToLower(name)
+	maxDistance := min(2, len([]rune(name))/3)
+	if maxDistance == 0 {
+		return nil
+	}
+
+	type match struct {
+		name     string
+		distance int
+	}
+
+	var matches []match
+	for _, cmd := range r.Commands() {
+		if cmd.Hidden || !cmd.PrefixCommand {
+			continue
+		}
+
+		best := maxDistance + 1
+		for _, candidate := range append([]string{cmd.Name}, cmd.Aliases...) {
+			best = min(best, editDistance(name, strings.ToLower(candidate)))
+		}
+		if best <= maxDistance {
+			matches = append(matches, match{name: cmd.Name, distance: best})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return a.distance - b.distance
+	})
+
+	names := make([]string, 0, min(len(matches), maxSuggestions))
+	for _, m := range matches[:min(len(matches), maxSuggestions)] {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// editDistance is the Damerau-Levenshtein distance (optimal string alignment)
+// between a and b, so swapped letters like "plya" count as a single edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "play", want: 4},
+		{a: "play", b: "play", want: 0},
+		{a: "plya", b: "play", want: 1},
+		{a: "paly", b: "play", want: 1},
+		{a: "ply", b: "play", want: 1},
+		{a: "plays", b: "play", want: 1},
+		{a: "pley", b: "play", want: 1},
+		{a: "abcd", b: "badc", want: 2},
+		{a: "kitten", b: "sitting", want: 3},
+		// Optimal string alignment doesn't edit a substring twice, so this
+		// isn't a transposition plus an insertion.
+		{a: "ca", b: "abc", want: 3},
+		{a: "fila", b: "filá", want: 1},
+		{a: "ação", b: "acao", want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestClosestCommands(t *testing.T) {
+	r := New(Options{})
+	for _, cmd := range []*Command{
+		{Name: "play", Aliases: []string{"p"}, PrefixCommand: true},
+		{Name: "pause", PrefixCommand: true},
+		{Name: "skip", Aliases: []string{"s", "next"}, PrefixCommand: true},
+		{Name: "queue", Aliases: []string{"q"}, PrefixCommand: true},
+		{Name: "equalizer", Aliases: []string{"eq"}, PrefixCommand: true},
+		{Name: "remove", PrefixCommand: true},
+		{Name: "resume", PrefixCommand: true},
+		{Name: "policy", Hidden: true, PrefixCommand: true},
+		{Name: "stats"},
+	} {
+		r.Add(cmd)
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "plya", want: []string{"play"}},
+		{name: "paly", want: []string{"play"}},
+		{name: "PLYA", want: []string{"play"}},
+		{name: "pauze", want: []string{"pause"}},
+		{name: "queu", want: []string{"queue"}},
+		{name: "nxt", want: []string{"skip"}},
+		{name: "equaliser", want: []string{"equalizer"}},
+		{name: "eqalizr", want: []string{"equalizer"}},
+		{name: "remume", want: []string{"resume", "remove"}},
+		// Names up to two letters are too short to guess from.
+		{name: "pl", want: nil},
+		{name: "sk", want: nil},
+		// Up to five letters one edit is allowed, from six on two.
+		{name: "qeeu", want: nil},
+		{name: "eqlzr", want: nil},
+		{name: "eqalzr", want: nil},
+		{name: "xyzzy", want: nil},
+		{name: "polcy", want: nil},
+		{name: "stast", want: nil},
+	}
+
+	for _, tt := range tests {
+		got := r.closestCommands(tt.name)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("closestCommands(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// RegisterSlash syncs the application commands with Discord, globally or in
+// every development guild. Only the commands that changed are sent, so calling
+// it on every Ready is cheap and doesn't restart global propagation. With
+// development guilds, global commands are deleted since they would show up
+// twice in those guilds.
+func (r *Registry) RegisterSlash(client bot.Client) error {
+	commands := r.applicationCommands()
+
+	if len(r.devGuilds) == 0 {
+		return syncCommands(client, nil, commands)
+	}
+
+	var errs []error
+	if err := syncCommands(client, nil, nil); err != nil {
+		errs = append(errs, err)
+	}
+	for _, guildID := range r.devGuilds {
+		if err := syncCommands(client, &guildID, commands); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) applicationCommands() []discord.ApplicationCommandCreate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	commands := make([]discord.ApplicationCommandCreate, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		if cmd.SlashCommand {
+			commands = append(commands, cmd.slashCommandCreate(r.catalog))
+		}
+		commands = append(commands, cmd.contextMenuCommandCreates(r.catalog)...)
+	}
+	return commands
+}
+
+type syncSummary struct {
+	created   []string
+	updated   []string
+	deleted   []string
+	unchanged int
+}
+
+func syncCommands(client bot.Client, guildID *snowflake.ID, commands []discord.ApplicationCommandCreate) error {
+	rest := client.Rest()
+	appID := client.ApplicationID()
+
+	scope := "global"
+	var (
+		existing []discord.ApplicationCommand
+		err      error
+	)
+	if guildID != nil {
+		scope = "guild " + guildID.String()
+		existing, err = rest.GetGuildCommands(appID, *guildID, true)
+	} else {
+		existing, err = rest.GetGlobalCommands(appID, true)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s commands: %w", scope, err)
+	}
+
+	current := make(map[string]discord.ApplicationCommand, len(existing))
+	for _, cmd := range existing {
+		current[commandKey(cmd.Type(), cmd.Name())] = cmd
+	}
+
+	// Creating a command with the name of an existing one overwrites it, so
+	// creates and updates go through the same call.
+	upsert := func(cmd discord.ApplicationCommandCreate) error {
+		if guildID != nil {
+			_, err := rest.CreateGuildCommand(appID, *guildID, cmd)
+			return err
+		}
+		_, err := rest.CreateGlobalCommand(appID, cmd)
+		return err
+	}
+
+	var summary syncSummary
+	for _, cmd := range commands {
+		key := commandKey(cmd.Type(), cmd.CommandName())
+		old, ok := current[key]
+		delete(current, key)
+
+		if ok {
+			same, err := commandUpToDate(old, cmd)
+			if err != nil {
+				return fmt.Errorf("failed to compare command %q: %w", cmd.CommandName(), err)
+			}
+			if same {
+				summary.unchanged++
+				continue
+			}
+		}
+
+		if err := upsert(cmd); err != nil {
+			return fmt.Errorf("failed to register command %q: %w", cmd.CommandName(), err)
+		}
+		if ok {
+			summary.updated = append(summary.updated, cmd.CommandName())
+		} else {
+			summary.created = append(summary.created, cmd.CommandName())
+		}
+	}
+
+	for _, cmd := range current {
+		if guildID != nil {
+			err = rest.DeleteGuildCommand(appID, *guildID, cmd.ID())
+		} else {
+			err = rest.DeleteGlobalCommand(appID, cmd.ID())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete command %q: %w", cmd.Name(), err)
+		}
+		summary.deleted = append(summary.deleted, cmd.Name())
+	}
+
+	slog.Info("Application commands synced",
+		slog.String("scope", scope),
+		slog.Any("created", summary.created),
+		slog.Any("updated", summary.updated),
+		slog.Any("deleted", summary.deleted),
+		slog.Int("unchanged", summary.unchanged),
+	)
+	return nil
+}
+
+func commandKey(commandType discord.ApplicationCommandType, name string) string {
+	return fmt.Sprintf("%d:%s", commandType, name)
+}
+
+// syncedFields are compared even when the registry leaves them out, an
+// omitted one has to be empty on Discord's side too. Other fields are only
+// compared when set, the ones left out keep Discord's defaults.
+var syncedFields = []string{"description", "options", "name_localizations", "description_localizations"}
+
+func commandUpToDate(existing discord.ApplicationCommand, create discord.ApplicationCommandCreate) (bool, error) {
+	have, err := jsonFields(existing)
+	if err != nil {
+		return false, err
+	}
+	want, err := jsonFields(create)
+	if err != nil {
+		return false, err
+	}
+
+	// A command without default member permissions comes back with none
+	// required, which is how the registry's null reads.
+	if have["default_member_permissions"] == "0" {
+		have["default_member_permissions"] = nil
+	}
+
+	for field, value := range want {
+		if !reflect.DeepEqual(value, have[field]) {
+			return false, nil
+		}
+	}
+	for _, field := range syncedFields {
+		if _, ok := want[field]; !ok && !isEmptyJSON(have[field]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func jsonFields(v json.Marshaler) (map[string]any, error) {
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func isEmptyJSON(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,372 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/i18n"
+)
+
+const syncAppID snowflake.ID = 1
+
+// registered returns cmd the way Discord sends it back once registered, with
+// edit applied to its JSON.
+func registered(t *testing.T, id snowflake.ID, cmd discord.ApplicationCommandCreate, edit func(fields map[string]any)) discord.ApplicationCommand {
+	t.Helper()
+
+	fields, err := jsonFields(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["id"] = id.String()
+	fields["application_id"] = syncAppID.String()
+	fields["version"] = "1"
+	fields["type"] = cmd.Type()
+	if _, ok := fields["default_member_permissions"]; !ok {
+		fields["default_member_permissions"] = nil
+	}
+	if edit != nil {
+		edit(fields)
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unmarshal discord.UnmarshalApplicationCommand
+	if err := json.Unmarshal(data, &unmarshal); err != nil {
+		t.Fatal(err)
+	}
+	return unmarshal.ApplicationCommand
+}
+
+func TestCommandUpToDate(t *testing.T) {
+	catalog := i18n.MustEmbedded(discord.LocaleEnglishUS)
+	play := &Command{
+		Name:         "play",
+		Description:  "Play a song.",
+		SlashCommand: true,
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{Name: "query", Description: "Song to play", Required: true},
+		},
+	}
+	create := play.slashCommandCreate(catalog)
+
+	tests := []struct {
+		name string
+		edit func(fields map[string]any)
+		want bool
+	}{
+		{name: "unchanged", want: true},
+		{
+			name: "fields the registry doesn't set",
+			edit: func(fields map[string]any) {
+				fields["dm_permission"] = true
+				fields["guild_id"] = "100"
+			},
+			want: true,
+		},
+		{
+			name: "permissions 0 is none",
+			edit: func(fields map[string]any) { fields["default_member_permissions"] = "0" },
+			want: true,
+		},
+		{
+			name: "permissions required on Discord",
+			edit: func(fields map[string]any) { fields["default_member_permissions"] = "8" },
+		},
+		{
+			name: "description changed",
+			edit: func(fields map[string]any) { fields["description"] = "Play music." },
+		},
+		{
+			name: "option changed",
+			edit: func(fields map[string]any) {
+				fields["options"].([]any)[0].(map[string]any)["required"] = false
+			},
+		},
+		{
+			name: "option removed",
+			edit: func(fields map[string]any) { fields["options"] = []any{} },
+		},
+		{
+			name: "localization on Discord only",
+			edit: func(fields map[string]any) {
+				fields["description_localizations"] = map[string]any{"pt-BR": "Toca uma música."}
+			},
+		},
+		{
+			name: "empty localizations",
+			edit: func(fields map[string]any) { fields["name_localizations"] = map[string]any{} },
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := commandUpToDate(registered(t, 10, create, tt.edit), create)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got up to date %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandUpToDatePermissions(t *testing.T) {
+	catalog := i18n.MustEmbedded(discord.LocaleEnglishUS)
+
+	tests := []struct {
+		name     string
+		local    discord.Permissions
+		existing any
+		want     bool
+	}{
+		{name: "null and null", existing: nil, want: true},
+		{name: "null and 0", existing: "0", want: true},
+		{name: "null and required", existing: "32"},
+		{name: "required and null", local: discord.PermissionManageGuild, existing: nil},
+		{name: "required and 0", local: discord.PermissionManageGuild, existing: "0"},
+		{name: "same required", local: discord.PermissionManageGuild, existing: "32", want: true},
+		{name: "other required", local: discord.PermissionManageGuild, existing: "8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create := (&Command{
+				Name:                     "policy",
+				Description:              "Restrict commands.",
+				SlashCommand:             true,
+				DefaultMemberPermissions: tt.local,
+			}).slashCommandCreate(catalog)
+
+			existing := registered(t, 10, create, func(fields map[string]any) {
+				fields["default_member_permissions"] = tt.existing
+			})
+			got, err := commandUpToDate(existing, create)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got up to date %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandUpToDateLocalizations(t *testing.T) {
+	catalog := i18n.MustEmbedded(discord.LocaleEnglishUS)
+	create := (&Command{Name: "skip", Description: "Skip the current song.", SlashCommand: true}).slashCommandCreate(catalog)
+	if create.DescriptionLocalizations[discord.LocalePortugueseBR] == "" {
+		t.Fatal("skip has no Portuguese description to test with")
+	}
+
+	tests := []struct {
+		name string
+		edit func(fields map[string]any)
+		want bool
+	}{
+		{name: "unchanged", want: true},
+		{
+			name: "translation changed",
+			edit: func(fields map[string]any) {
+				fields["description_localizations"].(map[string]any)["pt-BR"] = "Pula."
+			},
+		},
+		{
+			name: "translation missing on Discord",
+			edit: func(fields map[string]any) { delete(fields, "description_localizations") },
+		},
+		{
+			name: "extra translation on Discord",
+			edit: func(fields map[string]any) {
+				fields["description_localizations"].(map[string]any)["fr"] = "Passer."
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := commandUpToDate(registered(t, 10, create, tt.edit), create)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got up to date %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// syncRest is the part of the REST API commands are synced with, keeping the
+// registered commands per scope, "global" or a guild ID.
+type syncRest struct {
+	rest.Rest
+	t        *testing.T
+	commands map[string][]discord.ApplicationCommand
+	calls    []string
+	lastID   snowflake.ID
+}
+
+func (r *syncRest) get(scope string) ([]discord.ApplicationCommand, error) {
+	return slices.Clone(r.commands[scope]), nil
+}
+
+func (r *syncRest) upsert(scope string, create discord.ApplicationCommandCreate) (discord.ApplicationCommand, error) {
+	r.lastID++
+	cmd := registered(r.t, r.lastID, create, nil)
+	r.commands[scope] = slices.DeleteFunc(r.commands[scope], func(c discord.ApplicationCommand) bool {
+		return c.Type() == cmd.Type() && c.Name() == cmd.Name()
+	})
+	r.commands[scope] = append(r.commands[scope], cmd)
+	r.calls = append(r.calls, fmt.Sprintf("%s: put %s", scope, create.CommandName()))
+	return cmd, nil
+}
+
+func (r *syncRest) delete(scope string, commandID snowflake.ID) error {
+	for i, cmd := range r.commands[scope] {
+		if cmd.ID() == commandID {
+			r.calls = append(r.calls, fmt.Sprintf("%s: delete %s", scope, cmd.Name()))
+			r.commands[scope] = slices.Delete(r.commands[scope], i, i+1)
+			return nil
+		}
+	}
+	r.t.Fatalf("deleted unknown %s command %s", scope, commandID)
+	return nil
+}
+
+func (r *syncRest) GetGlobalCommands(_ snowflake.ID, _ bool, _ ...rest.RequestOpt) ([]discord.ApplicationCommand, error) {
+	return r.get("global")
+}
+
+func (r *syncRest) CreateGlobalCommand(_ snowflake.ID, create discord.ApplicationCommandCreate, _ ...rest.RequestOpt) (discord.ApplicationCommand, error) {
+	return r.upsert("global", create)
+}
+
+func (r *syncRest) DeleteGlobalCommand(_ snowflake.ID, commandID snowflake.ID, _ ...rest.RequestOpt) error {
+	return r.delete("global", commandID)
+}
+
+func (r *syncRest) GetGuildCommands(_ snowflake.ID, guildID snowflake.ID, _ bool, _ ...rest.RequestOpt) ([]discord.ApplicationCommand, error) {
+	return r.get(guildID.String())
+}
+
+func (r *syncRest) CreateGuildCommand(_ snowflake.ID, guildID snowflake.ID, create discord.ApplicationCommandCreate, _ ...rest.RequestOpt) (discord.ApplicationCommand, error) {
+	return r.upsert(guildID.String(), create)
+}
+
+func (r *syncRest) DeleteGuildCommand(_ snowflake.ID, guildID snowflake.ID, commandID snowflake.ID, _ ...rest.RequestOpt) error {
+	return r.delete(guildID.String(), commandID)
+}
+
+type syncClient struct {
+	bot.Client
+	rest *syncRest
+}
+
+func (c *syncClient) Rest() rest.Rest {
+	return c.rest
+}
+
+func (c *syncClient) ApplicationID() snowflake.ID {
+	return syncAppID
+}
+
+func TestRegisterSlash(t *testing.T) {
+	command := func(name, description string) *Command {
+		return &Command{Name: name, Description: description, SlashCommand: true}
+	}
+
+	tests := []struct {
+		name      string
+		devGuilds []snowflake.ID
+		before    []*Command
+		after     []*Command
+		calls     []string
+	}{
+		{
+			name:  "first run",
+			after: []*Command{command("play", "Play a song."), command("skip", "Skip the song.")},
+			calls: []string{"global: put play", "global: put skip"},
+		},
+		{
+			name:   "unchanged",
+			before: []*Command{command("play", "Play a song."), command("skip", "Skip the song.")},
+			after:  []*Command{command("play", "Play a song."), command("skip", "Skip the song.")},
+		},
+		{
+			name:   "changed",
+			before: []*Command{command("play", "Play a song."), command("skip", "Skip the song.")},
+			after:  []*Command{command("play", "Play music."), command("skip", "Skip the song.")},
+			calls:  []string{"global: put play"},
+		},
+		{
+			name:   "removed",
+			before: []*Command{command("play", "Play a song."), command("skip", "Skip the song.")},
+			after:  []*Command{command("play", "Play a song.")},
+			calls:  []string{"global: delete skip"},
+		},
+		{
+			name:   "context menu sharing a slash name",
+			before: []*Command{command("play", "Play a song.")},
+			after: []*Command{
+				command("play", "Play a song."),
+				{Name: "Add to queue", MessageCommand: true},
+			},
+			calls: []string{"global: put Add to queue"},
+		},
+		{
+			name:      "development guilds",
+			devGuilds: []snowflake.ID{100, 101},
+			before:    []*Command{command("play", "Play a song.")},
+			after:     []*Command{command("play", "Play a song.")},
+			calls:     []string{"global: delete play", "100: put play", "101: put play"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &syncClient{rest: &syncRest{t: t, commands: make(map[string][]discord.ApplicationCommand)}}
+
+			if tt.before != nil {
+				before := New(Options{})
+				for _, cmd := range tt.before {
+					before.Add(cmd)
+				}
+				if err := before.RegisterSlash(client); err != nil {
+					t.Fatal(err)
+				}
+				client.rest.calls = nil
+			}
+
+			after := New(Options{DevGuilds: tt.devGuilds})
+			for _, cmd := range tt.after {
+				after.Add(cmd)
+			}
+			if err := after.RegisterSlash(client); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(client.rest.calls, tt.calls) {
+				t.Errorf("got calls %q, want %q", client.rest.calls, tt.calls)
+			}
+
+			// Syncing again has nothing left to do.
+			client.rest.calls = nil
+			if err := after.RegisterSlash(client); err != nil {
+				t.Fatal(err)
+			}
+			if len(client.rest.calls) != 0 {
+				t.Errorf("got calls %q on the second sync, want none", client.rest.calls)
+			}
+		})
+	}
+}
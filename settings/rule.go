@@ -0,0 +1,78 @@
+package settings
+
+import (
+	"slices"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Rule restricts where a command or module can be used. Deny lists win over
+// allow lists, and an empty allow list allows everything.
+type Rule struct {
+	Disabled        bool           `json:"disabled,omitempty"`
+	AllowedChannels []snowflake.ID `json:"allowed_channels,omitempty"`
+	DeniedChannels  []snowflake.ID `json:"denied_channels,omitempty"`
+	AllowedRoles    []snowflake.ID `json:"allowed_roles,omitempty"`
+	DeniedRoles     []snowflake.ID `json:"denied_roles,omitempty"`
+}
+
+// Verdict is why a rule rejected an invocation.
+type Verdict int
+
+const (
+	Allowed Verdict = iota
+	DeniedDisabled
+	DeniedChannel
+	DeniedRole
+)
+
+// Check evaluates the rule for a member with the given roles using it in the
+// channel.
+func (r Rule) Check(channelID snowflake.ID, roleIDs []snowflake.ID) Verdict {
+	if r.Disabled {
+		return DeniedDisabled
+	}
+
+	if slices.Contains(r.DeniedChannels, channelID) ||
+		len(r.AllowedChannels) > 0 && !slices.Contains(r.AllowedChannels, channelID) {
+		return DeniedChannel
+	}
+
+	hasAny := func(roles []snowflake.ID) bool {
+		return slices.ContainsFunc(roleIDs, func(id snowflake.ID) bool {
+			return slices.Contains(roles, id)
+		})
+	}
+	if hasAny(r.DeniedRoles) || len(r.AllowedRoles) > 0 && !hasAny(r.AllowedRoles) {
+		return DeniedRole
+	}
+
+	return Allowed
+}
+
+// IsZero reports whether the rule doesn't restrict anything.
+func (r Rule) IsZero() bool {
+	return !r.Disabled &&
+		len(r.AllowedChannels) == 0 && len(r.DeniedChannels) == 0 &&
+		len(r.AllowedRoles) == 0 && len(r.DeniedRoles) == 0
+}
+
+func (r Rule) clone() Rule {
+	r.AllowedChannels = slices.Clone(r.AllowedChannels)
+	r.DeniedChannels = slices.Clone(r.DeniedChannels)
+	r.AllowedRoles = slices.Clone(r.AllowedRoles)
+	r.DeniedRoles = slices.Clone(r.DeniedRoles)
+	return r
+}
+
+func cloneRules(rules map[string]Rule) map[string]Rule {
+	if rules == nil {
+		return nil
+	}
+
+	cloned := make(map[string]Rule, len(rules))
+	for key, rule := range rules {
+		cloned[key] = rule.clone()
+	}
+	return cloned
+}
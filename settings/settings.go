@@ -0,0 +1,109 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/goland-express/flexo/utils"
+)
+
+// Guild holds the settings a guild can change, the zero value means every
+// setting uses its default.
+type Guild struct {
+	Prefixes           []string `json:"prefixes,omitempty"`
+	DisableSuggestions bool     `json:"disable_suggestions,omitempty"`
+	Language           string   `json:"language,omitempty"`
+
+	// Commands and Modules restrict where commands can be used, keyed by
+	// command path (like "queue shuffle") and module name.
+	Commands map[string]Rule `json:"commands,omitempty"`
+	Modules  map[string]Rule `json:"modules,omitempty"`
+
+	// VoiceChannels are the only voice channels the bot may join, any when
+	// empty.
+	VoiceChannels []snowflake.ID `json:"voice_channels,omitempty"`
+}
+
+func (g Guild) clone() Guild {
+	g.Prefixes = slices.Clone(g.Prefixes)
+	g.Commands = cloneRules(g.Commands)
+	g.Modules = cloneRules(g.Modules)
+	g.VoiceChannels = slices.Clone(g.VoiceChannels)
+	return g
+}
+
+// Store keeps the guild settings in memory and persists them to a JSON file
+// on every change.
+type Store struct {
+	mu     sync.RWMutex
+	path   string
+	guilds map[snowflake.ID]Guild
+}
+
+// Open loads the settings stored at path, a missing file is treated as empty.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:   path,
+		guilds: make(map[snowflake.ID]Guild),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.guilds); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) Guild(guildID snowflake.ID) Guild {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.guilds[guildID].clone()
+}
+
+// Update applies update to the guild's settings and saves them. Nothing is
+// changed when saving fails.
+func (s *Store) Update(guildID snowflake.ID, update func(g *Guild)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, existed := s.guilds[guildID]
+	guild := old.clone()
+	update(&guild)
+	s.guilds[guildID] = guild
+
+	if err := s.save(); err != nil {
+		if existed {
+			s.guilds[guildID] = old
+		} else {
+			delete(s.guilds, guildID)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the settings to their file, see utils.WriteFileAtomic.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.guilds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
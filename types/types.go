@@ -4,9 +4,11 @@ import (
 	"time"
 
 	"github.com/goland-express/flexo/player"
+	"github.com/goland-express/flexo/settings"
 )
 
 type BotData struct {
 	StartTime time.Time
-	Player    *player.Player
+	Player    player.Manager
+	Settings  *settings.Store
 }
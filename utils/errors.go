@@ -1,9 +1,97 @@
 package utils
 
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrorKind tells what went wrong, which decides how an error is presented.
+type ErrorKind int
+
+const (
+	// KindBadInput is for invalid arguments and values, the default kind of
+	// user errors.
+	KindBadInput ErrorKind = iota
+	KindNotFound
+	KindForbidden
+	KindCooldown
+	KindUnavailable
+
+	// KindInternal is for everything that isn't a UserError, bugs and
+	// failures of Discord, Lavalink or the disk.
+	KindInternal
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindBadInput:
+		return "bad_input"
+	case KindNotFound:
+		return "not_found"
+	case KindForbidden:
+		return "forbidden"
+	case KindCooldown:
+		return "cooldown"
+	case KindUnavailable:
+		return "unavailable"
+	case KindInternal:
+		return "internal"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// UserError is shown to the user as is. Replies are ephemeral unless Public is
+// set.
 type UserError struct {
+	Kind    ErrorKind
 	Message string
+	Public  bool
 }
 
 func (e *UserError) Error() string {
 	return e.Message
 }
+
+// KindOf returns the kind of the first UserError in the chain, or
+// KindInternal when there is none.
+func KindOf(err error) ErrorKind {
+	var userErr *UserError
+	if errors.As(err, &userErr) {
+		return userErr.Kind
+	}
+	return KindInternal
+}
+
+// NewIncidentID returns a short random ID that ties what a user reports to the
+// logged error.
+func NewIncidentID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ErrorChain lists the messages of err and every error it wraps, outermost
+// first. Joined errors are walked depth first.
+func ErrorChain(err error) []string {
+	var chain []string
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		chain = append(chain, fmt.Sprintf("%T: %s", err, err))
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(wrapped.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, err := range wrapped.Unwrap() {
+				walk(err)
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
@@ -12,3 +12,13 @@ func FormatDuration(ms int) string {
 	seconds %= 60
 	return fmt.Sprintf("%d:%02d", minutes, seconds)
 }
+
+// Truncate shortens s to at most limit runes, ending it with an ellipsis when
+// anything was cut.
+func Truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}